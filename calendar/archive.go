@@ -0,0 +1,143 @@
+package calendar
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ilsft/Golendar/events"
+	"github.com/ilsft/Golendar/storage"
+	validators "github.com/ilsft/Golendar/utils"
+)
+
+const DefaultArchiveAfter = 365 * 24 * time.Hour
+
+const (
+	archivedMessage   = "Перенесено в архив событий: %d"
+	archivedYearLine  = "  %d: %d"
+	noArchiveMessage  = "Нет событий старше %d дн. для архивации"
+	archivedMark      = " - 📦 архив"
+	errorArchiveStore = "ошибка архива за %d год: %w"
+)
+
+var errNoArchive = errors.New("архив не настроен")
+
+type ArchiveOpener func(year int) (storage.Store, error)
+
+func ArchiveURI(pattern string) ArchiveOpener {
+	return func(year int) (storage.Store, error) {
+		return storage.Open(strings.ReplaceAll(pattern, "{year}", fmt.Sprint(year)))
+	}
+}
+
+func (c *Calendar) loadArchive(year int) (*Calendar, error) {
+	if archive, ok := c.archives[year]; ok {
+		return archive, nil
+	}
+	if c.OpenArchive == nil {
+		return nil, errNoArchive
+	}
+	s, err := c.OpenArchive(year)
+	if err != nil {
+		return nil, fmt.Errorf(errorArchiveStore, year, err)
+	}
+	archive := &Calendar{CalendarEvents: make(map[string]*events.Event)}
+	data, err := s.Load()
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return nil, fmt.Errorf(errorArchiveStore, year, err)
+	case len(data) > 0:
+		archive, err = decodeCalendar(data)
+		if err != nil {
+			return nil, fmt.Errorf(errorArchiveStore, year, err)
+		}
+	}
+	archive.Storage = s
+	if c.archives == nil {
+		c.archives = make(map[int]*Calendar)
+	}
+	c.archives[year] = archive
+	return archive, nil
+}
+
+func (c *Calendar) ArchiveEvents(olderThan time.Duration) (string, error) {
+	if c.OpenArchive == nil {
+		return "", errNoArchive
+	}
+	cutoff := validators.Now().Add(-olderThan)
+	old, err := c.query(func(idx EventIndex) ([]json.RawMessage, error) {
+		return idx.Range(time.Time{}, cutoff)
+	}, func(e *events.Event) bool {
+		return e.End(c.defaultDuration()).Before(cutoff)
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(old) == 0 {
+		return fmt.Sprintf(noArchiveMessage, int(olderThan.Hours()/24)), nil
+	}
+
+	byYear := make(map[int][]*events.Event)
+	for _, event := range old {
+		year := event.StartAt.In(c.displayZone()).Year()
+		byYear[year] = append(byYear[year], event)
+	}
+	years := make([]int, 0, len(byYear))
+	for year := range byYear {
+		years = append(years, year)
+	}
+	slices.Sort(years)
+
+	lines := []string{fmt.Sprintf(archivedMessage, len(old))}
+	for _, year := range years {
+		archive, err := c.loadArchive(year)
+		if err != nil {
+			return "", err
+		}
+		for _, event := range byYear[year] {
+			archive.CalendarEvents[event.ID] = event
+		}
+		err = archive.Save()
+		if err != nil {
+			delete(c.archives, year)
+			return "", fmt.Errorf(errorArchiveStore, year, err)
+		}
+		for _, event := range byYear[year] {
+			event.Reminder.Stop()
+			delete(c.CalendarEvents, event.ID)
+			c.emit(EventArchived, event.ID)
+		}
+		c.forget(byYear[year])
+		if !slices.Contains(c.ArchivedYears, year) {
+			c.ArchivedYears = append(c.ArchivedYears, year)
+			slices.Sort(c.ArchivedYears)
+		}
+		lines = append(lines, fmt.Sprintf(archivedYearLine, year, len(byYear[year])))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func (c *Calendar) ArchivedEvents() ([]*events.Event, error) {
+	var result []*events.Event
+	for _, year := range c.ArchivedYears {
+		archive, err := c.loadArchive(year)
+		if err != nil {
+			return nil, err
+		}
+		for _, event := range archive.CalendarEvents {
+			result = append(result, event)
+		}
+	}
+	sortByStart(result)
+	return result, nil
+}
+
+func (c *Calendar) isArchived(event *events.Event) bool {
+	_, current := c.CalendarEvents[event.ID]
+	return !current
+}
@@ -0,0 +1,85 @@
+package calendar
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ilsft/Golendar/events"
+)
+
+func TestArchiveByYear(t *testing.T) {
+	dir := t.TempDir()
+	c := NewCalendar(nil)
+	c.OpenArchive = ArchiveURI("zip://" + filepath.Join(dir, "calendar-{year}.zip"))
+	c.DisplayZone = time.UTC
+	add := func(id, title string, year int) {
+		c.CalendarEvents[id] = &events.Event{ID: id, Title: title, StartAt: time.Date(year, time.June, 1, 10, 0, 0, 0, time.UTC)}
+	}
+	add("a", "Отчёт за квартал", 2020)
+	add("b", "Отчёт за год", 2021)
+	add("c", "Отчёт будущий", time.Now().Year()+1)
+
+	if _, err := c.ArchiveEvents(30 * 24 * time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.CalendarEvents) != 1 || len(c.ArchivedYears) != 2 {
+		t.Fatalf("Ожидалось 1 текущее событие и 2 архива, получено %d и %v", len(c.CalendarEvents), c.ArchivedYears)
+	}
+
+	reopened := NewCalendar(nil)
+	reopened.OpenArchive = c.OpenArchive
+	reopened.ArchivedYears = c.ArchivedYears
+	reopened.CalendarEvents = c.CalendarEvents
+	list, err := reopened.ListEvents(ListOptions{Archived: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 3 || list[0].ID != "a" {
+		t.Errorf("list --archived должен вернуть все события по дате, получено %v", list)
+	}
+	msg, err := reopened.ShowSearch("отчёт", SearchAll)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(reopened.Search("отчёт", SearchAll)); got != 1 {
+		t.Errorf("Search по текущим событиям должен найти одно событие, найдено %d", got)
+	}
+	if !containsAll(msg, "Отчёт за квартал", "Отчёт за год", archivedMark) {
+		t.Errorf("Поиск должен включать архивные события:\n%s", msg)
+	}
+}
+
+func TestArchiveDropsUndoOfArchivedEvents(t *testing.T) {
+	c := NewCalendar(nil)
+	c.OpenArchive = ArchiveURI("zip://" + filepath.Join(t.TempDir(), "calendar-{year}.zip"))
+	c.CalendarEvents["a"] = &events.Event{ID: "a", Title: "Старый отчёт", StartAt: time.Date(2020, time.June, 1, 10, 0, 0, 0, time.UTC)}
+	c.CalendarEvents["b"] = &events.Event{ID: "b", Title: "Планёрка", StartAt: time.Now().Add(24 * time.Hour)}
+	for _, id := range []string{"a", "b"} {
+		if _, err := c.TagEvent(id, []string{"work"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := c.ArchiveEvents(30 * 24 * time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.UndoStack) != 1 || c.UndoStack[0].Changes[0].ID != "b" {
+		t.Fatalf("В истории отмены должно остаться только изменение текущего события: %+v", c.UndoStack)
+	}
+	if _, err := c.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.CalendarEvents["a"]; ok {
+		t.Error("Отмена не должна возвращать архивное событие в календарь")
+	}
+}
+
+func containsAll(s string, parts ...string) bool {
+	for _, p := range parts {
+		if !strings.Contains(s, p) {
+			return false
+		}
+	}
+	return true
+}
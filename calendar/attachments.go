@@ -0,0 +1,154 @@
+package calendar
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/ilsft/Golendar/logger"
+	"github.com/ilsft/Golendar/storage"
+)
+
+const (
+	attachedMessage    = "Файл %s прикреплён к событию %s"
+	attachmentsHeader  = "📎 Вложения события %s"
+	attachmentLine     = "  %s"
+	extractedMessage   = "Вложение %s сохранено в %s"
+	errorNoAttachments = "у события %s нет вложений"
+)
+
+var errAttachmentsDisabled = errors.New("вложения доступны только в zip-хранилище календаря")
+
+func (c *Calendar) attachments() (*storage.ZipStorage, error) {
+	z, ok := storage.As[*storage.ZipStorage](c.Storage)
+	if !ok {
+		return nil, errAttachmentsDisabled
+	}
+	return z, nil
+}
+
+func (c *Calendar) Attach(id string, file string) (string, error) {
+	z, err := c.attachments()
+	if err != nil {
+		return "", err
+	}
+	event, err := c.GetEventByID(id)
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	err = z.AddAttachment(event.ID, filepath.Base(file), data)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(attachedMessage, filepath.Base(file), event.Title), nil
+}
+
+func (c *Calendar) ShowAttachments(id string) (string, error) {
+	z, err := c.attachments()
+	if err != nil {
+		return "", err
+	}
+	event, err := c.GetEventByID(id)
+	if err != nil {
+		return "", err
+	}
+	names, err := z.Attachments(event.ID)
+	if err != nil {
+		return "", err
+	}
+	if len(names) == 0 {
+		return "", fmt.Errorf(errorNoAttachments, event.Title)
+	}
+	msgs := []string{fmt.Sprintf(attachmentsHeader, event.Title)}
+	for _, name := range names {
+		msgs = append(msgs, fmt.Sprintf(attachmentLine, name))
+	}
+	return strings.Join(msgs, "\n"), nil
+}
+
+func (c *Calendar) ExtractAttachment(id string, name string, dir string) (string, error) {
+	z, err := c.attachments()
+	if err != nil {
+		return "", err
+	}
+	event, err := c.GetEventByID(id)
+	if err != nil {
+		return "", err
+	}
+	data, err := z.Attachment(event.ID, name)
+	if err != nil {
+		return "", err
+	}
+	err = os.MkdirAll(dir, 0755)
+	if err != nil {
+		return "", err
+	}
+	target := filepath.Join(dir, filepath.Base(name))
+	err = os.WriteFile(target, data, 0644)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(extractedMessage, name, target), nil
+}
+
+// pruneAttachments removes files of events that can no longer come back:
+// not in the calendar, the trash, the undo/redo stacks or a yearly archive.
+func (c *Calendar) pruneAttachments() error {
+	z, ok := storage.As[*storage.ZipStorage](c.Storage)
+	if !ok {
+		return nil
+	}
+	ids, err := z.AttachedEvents()
+	if err != nil {
+		return err
+	}
+	var orphans []string
+	for _, id := range ids {
+		if !c.references(id) {
+			orphans = append(orphans, id)
+		}
+	}
+	if len(orphans) == 0 {
+		return nil
+	}
+	for _, year := range c.ArchivedYears {
+		archive, err := c.loadArchive(year)
+		if err != nil {
+			logger.Warn(err.Error(), "archive", year)
+			return nil
+		}
+		orphans = slices.DeleteFunc(orphans, func(id string) bool {
+			_, ok := archive.CalendarEvents[id]
+			return ok
+		})
+	}
+	if len(orphans) == 0 {
+		return nil
+	}
+	return z.RemoveAttachments(orphans...)
+}
+
+func (c *Calendar) references(id string) bool {
+	c.fetch(id)
+	if _, ok := c.CalendarEvents[id]; ok {
+		return true
+	}
+	if _, ok := c.Trash[id]; ok {
+		return true
+	}
+	for _, op := range slices.Concat(c.UndoStack, c.RedoStack) {
+		for _, ch := range op.Changes {
+			if ch.ID == id {
+				return true
+			}
+		}
+	}
+	return false
+}
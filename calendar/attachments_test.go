@@ -0,0 +1,51 @@
+package calendar
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ilsft/Golendar/events"
+	"github.com/ilsft/Golendar/storage"
+)
+
+func TestAttachmentsRemovedWithEvent(t *testing.T) {
+	dir := t.TempDir()
+	z := storage.NewZipStorage(filepath.Join(dir, "calendar.zip"))
+	c := NewCalendar(z)
+	c.CalendarEvents["a"] = &events.Event{ID: "a", Title: "Планёрка", StartAt: time.Now().Add(time.Hour)}
+	file := filepath.Join(dir, "agenda.txt")
+	if err := os.WriteFile(file, []byte("повестка"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Attach("a", file); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.ExtractAttachment("a", "agenda.txt", filepath.Join(dir, "out")); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "out", "agenda.txt")); err != nil || string(data) != "повестка" {
+		t.Errorf("Вложение извлечено неверно: %q, %v", data, err)
+	}
+
+	if _, err := c.DeleteEvent("a"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.EmptyTrash(); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if ids, _ := z.AttachedEvents(); len(ids) != 1 {
+		t.Fatalf("Вложение нужно сохранить, пока удаление можно отменить: %v", ids)
+	}
+	c.UndoStack = nil
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if ids, _ := z.AttachedEvents(); len(ids) != 0 {
+		t.Errorf("Вложения окончательно удалённого события должны удаляться: %v", ids)
+	}
+}
@@ -0,0 +1,163 @@
+package calendar
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ilsft/Golendar/events"
+	"github.com/ilsft/Golendar/logger"
+	"github.com/ilsft/Golendar/storage"
+)
+
+const (
+	backupRestoredMessage = "Календарь восстановлен из снимка %s"
+	backupNoDiffMessage   = "Снимок %s совпадает с текущим календарём"
+	diffOnlyInSnapshot    = "+ только в снимке: %s"
+	diffOnlyInCurrent     = "- только в текущем: %s"
+	diffChanged           = "~ изменено: %s → %s"
+)
+
+var (
+	errorBackupsDisabled = "резервное копирование отключено"
+	errorNoBackups       = "снимков нет"
+)
+
+type EventDiff struct {
+	Snapshot *events.Event
+	Current  *events.Event
+}
+
+func (c *Calendar) backups() (*storage.BackupStorage, error) {
+	b, ok := storage.As[*storage.BackupStorage](c.Storage)
+	if !ok {
+		return nil, errors.New(errorBackupsDisabled)
+	}
+	return b, nil
+}
+
+func (c *Calendar) ListBackups() (string, error) {
+	b, err := c.backups()
+	if err != nil {
+		return "", err
+	}
+	snapshots, err := b.List()
+	if err != nil {
+		return "", err
+	}
+	if len(snapshots) == 0 {
+		return "", errors.New(errorNoBackups)
+	}
+	var msgs []string
+	for _, s := range snapshots {
+		msgs = append(msgs, fmt.Sprintf("%s - %s - %s - %d байт", s.ID, s.Kind,
+			c.FormatDate(s.Time), s.Size))
+	}
+	return strings.Join(msgs, "\n"), nil
+}
+
+func (c *Calendar) loadSnapshot(id string) (*Calendar, error) {
+	b, err := c.backups()
+	if err != nil {
+		return nil, err
+	}
+	data, err := b.LoadSnapshot(id)
+	if err != nil {
+		return nil, err
+	}
+	return decodeCalendar(data)
+}
+
+func (c *Calendar) diff(snap *Calendar) []EventDiff {
+	err := c.loadEvents()
+	if err != nil {
+		logger.Error(err.Error())
+	}
+	var diffs []EventDiff
+	for id, s := range snap.CalendarEvents {
+		cur, ok := c.CalendarEvents[id]
+		if !ok || !sameEvent(s, cur) {
+			diffs = append(diffs, EventDiff{Snapshot: s, Current: cur})
+		}
+	}
+	for id, cur := range c.CalendarEvents {
+		if _, ok := snap.CalendarEvents[id]; !ok {
+			diffs = append(diffs, EventDiff{Current: cur})
+		}
+	}
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffStart(diffs[i]).Before(diffStart(diffs[j]))
+	})
+	return diffs
+}
+
+func (c *Calendar) DiffBackup(id string) (string, error) {
+	snap, err := c.loadSnapshot(id)
+	if err != nil {
+		return "", err
+	}
+	diffs := c.diff(snap)
+	if len(diffs) == 0 {
+		return fmt.Sprintf(backupNoDiffMessage, id), nil
+	}
+	var msgs []string
+	for _, d := range diffs {
+		switch {
+		case d.Current == nil:
+			msgs = append(msgs, fmt.Sprintf(diffOnlyInSnapshot, c.describeEvent(d.Snapshot)))
+		case d.Snapshot == nil:
+			msgs = append(msgs, fmt.Sprintf(diffOnlyInCurrent, c.describeEvent(d.Current)))
+		default:
+			msgs = append(msgs, fmt.Sprintf(diffChanged, c.describeEvent(d.Current), c.describeEvent(d.Snapshot)))
+		}
+	}
+	return strings.Join(msgs, "\n"), nil
+}
+
+func (c *Calendar) RestoreBackup(id string) (string, error) {
+	snap, err := c.loadSnapshot(id)
+	if err != nil {
+		return "", err
+	}
+	err = c.loadEvents()
+	if err != nil {
+		return "", err
+	}
+	rec := c.track()
+	for id, event := range c.CalendarEvents {
+		c.touch(rec, id)
+		event.Reminder.Stop()
+	}
+	for id := range snap.CalendarEvents {
+		c.touch(rec, id)
+	}
+	c.CalendarEvents = snap.CalendarEvents
+	for _, event := range c.CalendarEvents {
+		if event.ReminderDue() {
+			event.Reminder.Rearm(c)
+		}
+	}
+	c.commit(rec, opRestore, id)
+	return fmt.Sprintf(backupRestoredMessage, id), nil
+}
+
+func sameEvent(a *events.Event, b *events.Event) bool {
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(ja, jb)
+}
+
+func diffStart(d EventDiff) time.Time {
+	if d.Current != nil {
+		return d.Current.StartAt
+	}
+	return d.Snapshot.StartAt
+}
+
+func (c *Calendar) describeEvent(e *events.Event) string {
+	return fmt.Sprintf("%s - %s - %s", e.Title, c.FormatDate(e.StartAt), e.Priority)
+}
@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/ilsft/Golendar/events"
 	"github.com/ilsft/Golendar/logger"
@@ -13,65 +15,300 @@ import (
 )
 
 const (
-	eventAddedMessage     = "Событие: %s добавлено"
-	eventDeleteMessage    = "Событие: %s удалено"
-	eventEditTitleMessage = "Событие: %s обновлено на %s - %s"
-	reminderAddMessage    = "Напоминание: %s добавлено \n%s"
-	reminderDeleteMessage = "Напоминание удалено \n%s"
-	reminderCloseMessage  = "Канал Notification закрыт"
+	eventAddedMessage      = "Событие: %s добавлено на %s"
+	eventEditMessage       = "Событие: %s обновлено"
+	eventNotChangedMessage = "Событие: %s не изменилось"
+	reminderAddMessage     = "Напоминание: %s добавлено на %s \n%s"
+	reminderDeleteMessage  = "Напоминание удалено \n%s"
+	reminderCloseMessage   = "Канал Notification закрыт"
+	reminderNote           = "\nНапоминание на %s"
+	reminderSkipped        = "\nВнимание, напоминание не добавлено: время %s уже прошло"
+)
+
+const (
+	clockPattern = "15:04"
+	detailLine   = "%-14s %v"
+	diffLine     = "%-14s %s → %s"
 )
 
 var (
 	errorNotFoundID   = "не найдено событие с ID %s"
 	errorEmptyList    = "событий нет"
+	errorNoMatches    = "нет событий по заданным условиям"
 	errorSerialJSON   = "ошибка сериализации: %v"
 	errorDeSerialJSON = "ошибка десериализации: %v"
 	errorNotFoundRem  = "нет напоминания для удаления"
+	errorSortOrder    = "неизвестный порядок сортировки %q, доступны: date, priority"
 )
 
+var endOfTime = time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC)
+
 type Calendar struct {
-	CalendarEvents map[string]*events.Event `json:"events"`
-	Storage        storage.Store            `json:"-"`
-	Notification   chan string              `json:"-"`
+	Version         int                      `json:"version"`
+	CalendarEvents  map[string]*events.Event `json:"events"`
+	Storage         storage.Store            `json:"-"`
+	Notification    chan string              `json:"-"`
+	DisplayZone     *time.Location           `json:"-"`
+	Policies        validators.DatePolicies  `json:"-"`
+	DefaultDuration time.Duration            `json:"-"`
+	Schedule        Schedule                 `json:"-"`
+	Trash           map[string]*TrashItem    `json:"trash,omitempty"`
+	TrashRetention  time.Duration            `json:"-"`
+	ArchivedYears   []int                    `json:"archived_years,omitempty"`
+	OpenArchive     ArchiveOpener            `json:"-"`
+	ArchiveAfter    time.Duration            `json:"-"`
+	UndoStack       []Operation              `json:"undo,omitempty"`
+	RedoStack       []Operation              `json:"redo,omitempty"`
+	Templates       map[string]*Template     `json:"templates,omitempty"`
+	Journal         *Journal                 `json:"-"`
+	complete        bool
+	known           map[string]bool
+	archives        map[int]*Calendar
+}
+
+type ListOptions struct {
+	SecondZone *time.Location
+	Tags       TagFilter
+	Sort       SortOrder
+	Archived   bool
+	From       time.Time
+	To         time.Time
+	Priority   events.Priority
+}
+
+func (opts ListOptions) match(e *events.Event) bool {
+	return opts.Tags.Match(e) &&
+		(opts.From.IsZero() || !e.StartAt.Before(opts.From)) &&
+		(opts.To.IsZero() || !e.StartAt.After(opts.To)) &&
+		(opts.Priority == "" || e.Priority == opts.Priority)
+}
+
+type SortOrder string
+
+const (
+	SortByDate     SortOrder = "date"
+	SortByPriority SortOrder = "priority"
+)
+
+func ParseSortOrder(s string) (SortOrder, error) {
+	switch order := SortOrder(strings.ToLower(strings.TrimSpace(s))); order {
+	case "", SortByDate:
+		return SortByDate, nil
+	case SortByPriority:
+		return SortByPriority, nil
+	default:
+		return "", fmt.Errorf(errorSortOrder, s)
+	}
 }
 
 func NewCalendar(s storage.Store) *Calendar {
 	return &Calendar{
-		CalendarEvents: make(map[string]*events.Event),
-		Storage:        s,
-		Notification:   make(chan string, 5),
+		Version:         SchemaVersion,
+		CalendarEvents:  make(map[string]*events.Event),
+		Storage:         s,
+		Notification:    make(chan string, 5),
+		Policies:        validators.DefaultDatePolicies(),
+		DefaultDuration: events.DefaultDuration,
+		Schedule:        DefaultSchedule(),
+		Trash:           make(map[string]*TrashItem),
+		TrashRetention:  DefaultTrashRetention,
+		ArchiveAfter:    DefaultArchiveAfter,
+	}
+}
+
+func (c *Calendar) AddEvent(title string, dateStr string, priority events.Priority, details events.Details, opts AddOptions) (string, error) {
+	event, err := events.NewEvent(title, dateStr, priority, c.Policies.Create)
+	if err != nil {
+		return "", err
 	}
+	return c.addEvent(event, details, opts)
 }
 
-func (c *Calendar) AddEvent(title string, dateStr string, priority events.Priority) (string, error) {
-	event, err := events.NewEvent(title, dateStr, priority)
+func (c *Calendar) AddEventAt(title string, start time.Time, priority events.Priority, details events.Details, opts AddOptions) (string, error) {
+	event, err := events.NewEventAt(title, start, priority, c.Policies.Create)
+	if err != nil {
+		return "", err
+	}
+	return c.addEvent(event, details, opts)
+}
+
+func (c *Calendar) addEvent(event *events.Event, details events.Details, opts AddOptions) (string, error) {
+	err := event.SetDetails(details)
+	if err != nil {
+		return "", err
+	}
+	if len(opts.Tags) > 0 {
+		_, err = event.AddTags(opts.Tags...)
+		if err != nil {
+			return "", err
+		}
+	}
+	op, msg, warning := opAdd, eventAddedMessage, ""
+	if opts.Task {
+		op, msg = opAddTask, taskAddedMessage
+		err = event.MakeTask(opts.Subtasks)
+	} else {
+		err = event.SetEnd(opts.End)
+		if err == nil {
+			warning, err = c.checkClashes(event, opts.Force)
+		}
+	}
 	if err != nil {
 		return "", err
 	}
+	if r := opts.Reminder; r != nil {
+		at := event.StartAt.Add(-r.Before)
+		if at.After(validators.Now()) {
+			_, err = event.AddReminder(r.Message, at, c)
+			if err != nil {
+				return "", err
+			}
+			warning = fmt.Sprintf(reminderNote, c.FormatDate(at)) + warning
+		} else {
+			warning = fmt.Sprintf(reminderSkipped, c.FormatDate(at)) + warning
+		}
+	}
+	rec := c.track(event.ID)
 	c.CalendarEvents[event.ID] = event
-	return fmt.Sprintf(eventAddedMessage, event.Title), nil
+	c.commit(rec, op, event.Title)
+	return fmt.Sprintf(msg, event.Title, c.FormatDate(event.StartAt)) + warning, nil
+}
+
+func (c *Calendar) ListEvents(opts ListOptions) ([]*events.Event, error) {
+	var list []*events.Event
+	var err error
+	switch {
+	case !opts.From.IsZero() || !opts.To.IsZero():
+		to := opts.To
+		if to.IsZero() {
+			to = endOfTime
+		}
+		list, err = c.EventsInRange(opts.From, to)
+	case opts.Priority != "":
+		list, err = c.EventsByPriority(opts.Priority)
+	default:
+		list = c.scan(opts.match)
+	}
+	if err != nil {
+		return nil, err
+	}
+	list = slices.DeleteFunc(list, func(e *events.Event) bool {
+		return !opts.match(e)
+	})
+	if opts.Archived {
+		archived, err := c.ArchivedEvents()
+		if err != nil {
+			return nil, err
+		}
+		for _, event := range archived {
+			if opts.match(event) {
+				list = append(list, event)
+			}
+		}
+		sortByStart(list)
+	}
+	if opts.Sort == SortByPriority {
+		sortByPriority(list)
+	}
+	return list, nil
 }
 
-func (c *Calendar) ShowEvents() string {
-	if len(c.CalendarEvents) == 0 {
+func (c *Calendar) FormatEvents(list []*events.Event, opts ListOptions) string {
+	if len(list) == 0 && c.isEmpty() {
 		return errorEmptyList
 	}
+	if len(list) == 0 {
+		return errorNoMatches
+	}
+
 	var msgs []string
-	for _, event := range c.CalendarEvents {
-		msg := fmt.Sprintf("%s - %s - %v - %s", event.ID, event.Title,
-			validators.FormatDateEvent(event.StartAt), event.Priority)
+	for i, event := range list {
+		msg := fmt.Sprintf("#%d %s - %s - %s - %s", i+1, event.ID, event.Title,
+			c.formatEventDate(event, opts), event.Priority.Colorize())
+		if len(event.Tags) > 0 {
+			msg += " - " + event.FormatTags()
+		}
+		if event.IsTask() {
+			msg += " - " + c.formatTask(event)
+		}
+		if c.isArchived(event) {
+			msg += archivedMark
+		}
 		msgs = append(msgs, msg)
 
 		if event.Reminder != nil {
 			remMsg := fmt.Sprintf("Напоминание для: %s - %s - %s - %v", event.Title,
-				event.Reminder.Message, validators.FormatDateEvent(event.Reminder.At), event.Reminder.Sent)
+				event.Reminder.Message, c.FormatDate(event.Reminder.At), event.Reminder.Sent)
 			msgs = append(msgs, remMsg)
 		}
 	}
 	return strings.Join(msgs, "\n")
 }
 
+func (c *Calendar) ShowEvent(id string) (string, error) {
+	event, err := c.GetEventByID(id)
+	if err != nil {
+		return "", err
+	}
+	lines := []string{
+		fmt.Sprintf(detailLine, "ID", event.ID),
+		fmt.Sprintf(detailLine, "Название", event.Title),
+		fmt.Sprintf(detailLine, "Дата и время", c.formatEventDate(event, ListOptions{})),
+		fmt.Sprintf(detailLine, "Приоритет", event.Priority.Colorize()),
+	}
+	if event.EndAt != nil {
+		lines = append(lines, fmt.Sprintf(detailLine, "Окончание", c.formatEnd(event)))
+	}
+	if event.IsTask() {
+		lines = append(lines, fmt.Sprintf(detailLine, "Задача", c.formatTask(event)))
+		for _, s := range event.Task.Subtasks {
+			lines = append(lines, fmt.Sprintf(detailLine, "", subtaskMark(s)+" "+s.Title))
+		}
+	}
+	if len(event.Tags) > 0 {
+		lines = append(lines, fmt.Sprintf(detailLine, "Теги", event.FormatTags()))
+	}
+	if event.Description != "" {
+		lines = append(lines, fmt.Sprintf(detailLine, "Описание", event.Description))
+	}
+	if event.Location != "" {
+		lines = append(lines, fmt.Sprintf(detailLine, "Место", event.Location))
+	}
+	if event.URL != "" {
+		lines = append(lines, fmt.Sprintf(detailLine, "Ссылка", event.URL))
+	}
+	if event.Reminder != nil {
+		lines = append(lines, fmt.Sprintf(detailLine, "Напоминание",
+			fmt.Sprintf("%s - %s - %v", event.Reminder.Message, c.FormatDate(event.Reminder.At), event.Reminder.Sent)))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func (c *Calendar) displayZone() *time.Location {
+	if c.DisplayZone == nil {
+		return time.Local
+	}
+	return c.DisplayZone
+}
+
+func (c *Calendar) FormatDate(t time.Time) string {
+	return validators.FormatDateEvent(t.In(c.displayZone()))
+}
+
+func (c *Calendar) formatEventDate(event *events.Event, opts ListOptions) string {
+	if opts.SecondZone == nil {
+		msg := c.FormatDate(event.StartAt)
+		if event.TimeZone != "" && event.TimeZone != c.displayZone().String() {
+			msg += fmt.Sprintf(" (%s %s)", event.StartAt.In(event.Zone()).Format(clockPattern), event.TimeZone)
+		}
+		return msg
+	}
+	return fmt.Sprintf("%s | %s", validators.FormatDateZone(event.StartAt.In(c.displayZone())),
+		validators.FormatDateZone(event.StartAt.In(opts.SecondZone)))
+}
+
 func (c *Calendar) GetEventByID(id string) (*events.Event, error) {
+	c.fetch(id)
 	e, exist := c.CalendarEvents[id]
 	if !exist {
 		return nil, fmt.Errorf(errorNotFoundID, id)
@@ -79,41 +316,140 @@ func (c *Calendar) GetEventByID(id string) (*events.Event, error) {
 	return e, nil
 }
 
-func (c *Calendar) DeleteEvent(id string) (string, error) {
+func (c *Calendar) EditEvent(id string, changes events.Changes, force bool) (string, error) {
 	event, err := c.GetEventByID(id)
 	if err != nil {
 		return "", err
 	}
-	delete(c.CalendarEvents, id)
-	return fmt.Sprintf(eventDeleteMessage, event.Title), nil
-}
-
-func (c *Calendar) EditEvent(id string, newTitle string, date string, priority events.Priority) (string, error) {
-	event, err := c.GetEventByID(id)
+	rec := c.track(id)
+	before := *event
+	err = event.Apply(changes, c.Policies.Edit)
 	if err != nil {
 		return "", err
 	}
-	oldTitle := c.CalendarEvents[id].Title
-	err = event.Update(newTitle, date, priority)
-	if err != nil {
-		return "", err
+	var warning string
+	if !event.StartAt.Equal(before.StartAt) || !event.End(c.defaultDuration()).Equal(before.End(c.defaultDuration())) {
+		warning, err = c.checkClashes(event, force)
+		if err != nil {
+			*event = before
+			return "", err
+		}
+	}
+	c.commit(rec, opEdit, before.Title)
+	diff := c.diffEvent(&before, event)
+	if len(diff) == 0 {
+		return fmt.Sprintf(eventNotChangedMessage, event.Title), nil
+	}
+	return fmt.Sprintf(eventEditMessage, before.Title) + "\n" + strings.Join(diff, "\n") + warning, nil
+}
+
+func (c *Calendar) diffEvent(before, after *events.Event) []string {
+	var lines []string
+	for _, f := range []struct {
+		name   string
+		before string
+		after  string
+	}{
+		{"Название", before.Title, after.Title},
+		{"Дата и время", c.formatEventDate(before, ListOptions{}), c.formatEventDate(after, ListOptions{})},
+		{"Окончание", c.formatEnd(before), c.formatEnd(after)},
+		{"Приоритет", string(before.Priority), string(after.Priority)},
+		{"Описание", before.Description, after.Description},
+		{"Место", before.Location, after.Location},
+		{"Ссылка", before.URL, after.URL},
+	} {
+		if f.before != f.after {
+			lines = append(lines, fmt.Sprintf(diffLine, f.name, orDash(f.before), orDash(f.after)))
+		}
+	}
+	return lines
+}
+
+func (c *Calendar) formatEnd(e *events.Event) string {
+	if e.EndAt == nil {
+		return ""
+	}
+	return c.FormatDate(*e.EndAt)
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "—"
 	}
-	return fmt.Sprintf(eventEditTitleMessage, oldTitle, newTitle, date), nil
+	return s
 }
 
 func (c *Calendar) Save() error {
+	c.Version = SchemaVersion
 	data, err := json.Marshal(c)
 	if err != nil {
 		return fmt.Errorf(errorSerialJSON, err)
 	}
-	err = c.Storage.Save(data)
+	if s, ok := c.Storage.(storage.RecordStore); ok && c.known != nil {
+		err = c.saveRecords(s, data)
+	} else {
+		err = c.Storage.Save(data)
+	}
 	if err != nil {
 		return (err)
 	}
+	err = c.pruneAttachments()
+	if err != nil {
+		return err
+	}
+	if c.Journal != nil {
+		return c.Journal.Save()
+	}
+	return nil
+}
+
+// saveRecords writes the events read or changed since the calendar was
+// loaded; events that stayed in the index are left as they are.
+func (c *Calendar) saveRecords(s storage.RecordStore, data []byte) error {
+	var doc map[string]json.RawMessage
+	err := json.Unmarshal(data, &doc)
+	if err != nil {
+		return fmt.Errorf(errorSerialJSON, err)
+	}
+	var upserts map[string]json.RawMessage
+	err = json.Unmarshal(doc["events"], &upserts)
+	if err != nil {
+		return fmt.Errorf(errorSerialJSON, err)
+	}
+	delete(doc, "events")
+	meta, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf(errorSerialJSON, err)
+	}
+	var deletes []string
+	for id := range c.known {
+		if _, ok := c.CalendarEvents[id]; !ok {
+			deletes = append(deletes, id)
+		}
+	}
+	slices.Sort(deletes)
+	err = s.SaveRecords(meta, upserts, deletes)
+	if err != nil {
+		return err
+	}
+	c.known = make(map[string]bool, len(upserts))
+	for id := range upserts {
+		c.known[id] = true
+	}
 	return nil
 }
 
 func (c *Calendar) Load() error {
+	if idx, ok := c.index(); ok {
+		lazy, err := c.loadMeta(idx)
+		if err != nil {
+			c.Notify(err.Error())
+			return err
+		}
+		if lazy {
+			return nil
+		}
+	}
 	data, err := c.Storage.Load()
 	if err != nil {
 		c.Notify(err.Error())
@@ -123,29 +459,112 @@ func (c *Calendar) Load() error {
 		if c.CalendarEvents == nil {
 			c.CalendarEvents = make(map[string]*events.Event)
 		}
+		c.loaded()
 		return nil
 	}
+	data, err = c.migrate(data)
+	if err != nil {
+		return err
+	}
 	err = json.Unmarshal(data, c)
 	if err != nil {
 		return fmt.Errorf(errorDeSerialJSON, err)
 	}
+	for _, event := range c.CalendarEvents {
+		event.Restore()
+	}
+	if c.Trash == nil {
+		c.Trash = make(map[string]*TrashItem)
+	}
+	for _, item := range c.Trash {
+		item.Event.Restore()
+	}
+	c.baseline()
+	c.PurgeTrash()
+	c.loaded()
 	return nil
 }
 
+// loadMeta reads everything but the events from the index, the events are
+// read on demand. Older documents are loaded whole to run the migrations.
+func (c *Calendar) loadMeta(idx EventIndex) (bool, error) {
+	meta, err := idx.Meta()
+	if err != nil || len(meta) == 0 {
+		return false, err
+	}
+	_, version, err := decodeDocument(meta)
+	if err != nil || version != SchemaVersion {
+		return false, err
+	}
+	err = json.Unmarshal(meta, c)
+	if err != nil {
+		return false, fmt.Errorf(errorDeSerialJSON, err)
+	}
+	if c.CalendarEvents == nil {
+		c.CalendarEvents = make(map[string]*events.Event)
+	}
+	if c.Trash == nil {
+		c.Trash = make(map[string]*TrashItem)
+	}
+	for _, item := range c.Trash {
+		item.Event.Restore()
+	}
+	c.complete = false
+	c.known = make(map[string]bool)
+	c.baseline()
+	c.PurgeTrash()
+	return true, nil
+}
+
+func (c *Calendar) loaded() {
+	c.complete = true
+	if _, ok := c.index(); ok {
+		c.known = make(map[string]bool, len(c.CalendarEvents))
+		for id := range c.CalendarEvents {
+			c.known[id] = true
+		}
+	}
+}
+
+// isEmpty reports whether the calendar has no events, counting the ones
+// that are still only in the index.
+func (c *Calendar) isEmpty() bool {
+	if len(c.CalendarEvents) > 0 {
+		return false
+	}
+	idx, ok := c.index()
+	if !ok || c.complete {
+		return true
+	}
+	stored, err := idx.Len()
+	if err != nil {
+		logger.Error(err.Error())
+		return true
+	}
+	return stored <= len(c.known)
+}
+
 func (c *Calendar) SetEventReminder(id string, message string, time string) (string, error) {
 	event, err := c.GetEventByID(id)
 	if err != nil {
 		return "", err
 	}
 	t, errValid := validators.ValidateDate(time)
+	if errValid == nil {
+		errValid = validators.DateFutureOnly.Check(t)
+	}
 	if errValid != nil {
 		return "", fmt.Errorf(events.ErrorValidReminder, errValid, message)
 	}
+	rec := c.track(id)
+	previous := event.Reminder
 	msg, err := event.AddReminder(message, t, c)
 	if err != nil {
 		return "", err
 	}
-	return fmt.Sprintf(reminderAddMessage, event.Reminder.Message, msg), nil
+	previous.Stop()
+	c.commit(rec, opReminderSet, event.Title)
+	return fmt.Sprintf(reminderAddMessage, event.Reminder.Message, c.FormatDate(event.Reminder.At), msg), nil
 }
 
 func (c *Calendar) RemoveEventReminder(id string) (string, error) {
@@ -156,7 +575,9 @@ func (c *Calendar) RemoveEventReminder(id string) (string, error) {
 	if event.Reminder == nil {
 		return "", errors.New(errorNotFoundRem)
 	}
+	rec := c.track(id)
 	msg := event.RemoveReminder()
+	c.commit(rec, opReminderRemove, event.Title)
 	return fmt.Sprintf(reminderDeleteMessage, msg), nil
 }
 
@@ -165,7 +586,9 @@ func (c *Calendar) CancelEventReminder(id string) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	msg := event.Reminder.Stop()
+	rec := c.track(id)
+	msg := event.Reminder.Pause()
+	c.commit(rec, opReminderStop, event.Title)
 	return msg, nil
 }
 
@@ -174,6 +597,6 @@ func (c *Calendar) Notify(msg string) {
 }
 
 func (c *Calendar) Close() {
-	logger.LogInfo(reminderCloseMessage)
+	logger.Info(reminderCloseMessage)
 	close(c.Notification)
 }
@@ -0,0 +1,124 @@
+package calendar
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ilsft/Golendar/events"
+)
+
+var ErrConflict = errors.New("событие пересекается с другими событиями")
+
+const (
+	errorConflict      = "%w:\n%s\nДобавьте --force, чтобы сохранить событие с пересечением"
+	conflictWarning    = "Внимание, пересечение с:\n%s"
+	conflictsHeader    = "Пересечения событий: %d"
+	noConflictsMessage = "Пересечений нет"
+	clashLine          = "  %s %s"
+	conflictLine       = "%s %s ↔ %s"
+	highMark           = "‼"
+	plainMark          = "•"
+)
+
+type AddOptions struct {
+	End      string
+	Force    bool
+	Task     bool
+	Subtasks []string
+	Tags     []string
+	Reminder *ReminderSpec
+}
+
+type ReminderSpec struct {
+	Message string
+	Before  time.Duration
+}
+
+type Conflict struct {
+	First  *events.Event
+	Second *events.Event
+}
+
+func (c Conflict) rank() int {
+	return max(c.First.Priority.Rank(), c.Second.Priority.Rank())
+}
+
+func (c *Calendar) defaultDuration() time.Duration {
+	if c.DefaultDuration <= 0 {
+		return events.DefaultDuration
+	}
+	return c.DefaultDuration
+}
+
+func (c *Calendar) Clashes(event *events.Event) []*events.Event {
+	d := c.defaultDuration()
+	list := c.lookup(func(idx EventIndex) ([]json.RawMessage, error) {
+		return idx.Overlapping(event.StartAt, event.End(d), d)
+	}, func(other *events.Event) bool {
+		return other.ID != event.ID && event.Overlaps(other, d)
+	})
+	sortByPriority(list)
+	return list
+}
+
+func (c *Calendar) Conflicts(from, to time.Time) []Conflict {
+	d := c.defaultDuration()
+	list := c.lookup(func(idx EventIndex) ([]json.RawMessage, error) {
+		return idx.Overlapping(from, to, d)
+	}, func(e *events.Event) bool {
+		return !e.IsTask() && (to.IsZero() || e.StartAt.Before(to)) && e.End(d).After(from)
+	})
+	var result []Conflict
+	for i, first := range list {
+		for _, second := range list[i+1:] {
+			if !second.StartAt.Before(first.End(d)) {
+				break
+			}
+			result = append(result, Conflict{First: first, Second: second})
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].rank() > result[j].rank()
+	})
+	return result
+}
+
+func (c *Calendar) ShowConflicts(from, to time.Time) string {
+	conflicts := c.Conflicts(from, to)
+	if len(conflicts) == 0 {
+		return noConflictsMessage
+	}
+	msgs := []string{fmt.Sprintf(conflictsHeader, len(conflicts))}
+	for _, conflict := range conflicts {
+		msgs = append(msgs, fmt.Sprintf(conflictLine, priorityMark(conflict.rank()),
+			c.describeEvent(conflict.First), c.describeEvent(conflict.Second)))
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func (c *Calendar) checkClashes(event *events.Event, force bool) (string, error) {
+	clashes := c.Clashes(event)
+	if len(clashes) == 0 {
+		return "", nil
+	}
+	lines := make([]string, 0, len(clashes))
+	for _, other := range clashes {
+		lines = append(lines, fmt.Sprintf(clashLine, priorityMark(other.Priority.Rank()), c.describeEvent(other)))
+	}
+	list := strings.Join(lines, "\n")
+	if !force {
+		return "", fmt.Errorf(errorConflict, ErrConflict, list)
+	}
+	return "\n" + fmt.Sprintf(conflictWarning, list), nil
+}
+
+func priorityMark(rank int) string {
+	if rank >= events.PriorityHigh.Rank() {
+		return highMark
+	}
+	return plainMark
+}
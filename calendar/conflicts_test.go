@@ -0,0 +1,44 @@
+package calendar
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ilsft/Golendar/events"
+)
+
+func TestConflictDetection(t *testing.T) {
+	c := NewCalendar(nil)
+	_, err := c.AddEvent("Планёрка", "2030-03-01 10:00", events.PriorityLow, events.Details{}, AddOptions{End: "30m"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.AddEvent("Релиз", "2030-03-01 12:00", events.PriorityHigh, events.Details{}, AddOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = c.AddEvent("Созвон", "2030-03-01 10:15", events.PriorityMedium, events.Details{}, AddOptions{})
+	if !errors.Is(err, ErrConflict) {
+		t.Fatalf("Ожидалась ошибка пересечения, получено: %v", err)
+	}
+	if _, err = c.AddEvent("Обед", "2030-03-01 10:30", events.PriorityLow, events.Details{}, AddOptions{}); err != nil {
+		t.Errorf("Событие сразу после окончания не пересекается: %v", err)
+	}
+	if _, err = c.AddEvent("Созвон", "2030-03-01 12:30", events.PriorityMedium, events.Details{}, AddOptions{Force: true}); err != nil {
+		t.Fatalf("С --force пересечение допускается: %v", err)
+	}
+
+	if _, err = c.AddEvent("Кофе", "2030-03-01 10:10", events.PriorityLow, events.Details{}, AddOptions{End: "10m", Force: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	conflicts := c.Conflicts(time.Time{}, time.Time{})
+	if len(conflicts) != 2 {
+		t.Fatalf("Ожидалось 2 пересечения, получено %d", len(conflicts))
+	}
+	if conflicts[0].First.Title != "Релиз" {
+		t.Errorf("Пересечение с высоким приоритетом должно быть первым, получено %s", conflicts[0].First.Title)
+	}
+}
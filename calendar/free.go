@@ -0,0 +1,234 @@
+package calendar
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ilsft/Golendar/events"
+)
+
+const (
+	slotStep     = 5 * time.Minute
+	DefaultSlots = 5
+)
+
+const (
+	freeHeader     = "Свободное время для %s:"
+	freeSlotLine   = "#%d %s – %s (свободно %s)"
+	noFreeMessage  = "Нет свободного времени длительностью %s в рабочие часы"
+	errorWorkDay   = "неизвестный день недели %q, ожидается mon, tue, wed, thu, fri, sat или sun"
+	errorWorkHours = "неверные рабочие часы %q для %s, ожидается формат 09:00-18:00"
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+type WorkingHours map[string]string
+
+type Schedule struct {
+	WorkingHours WorkingHours
+	BufferBefore time.Duration
+	BufferAfter  time.Duration
+	Slots        int
+}
+
+type Slot struct {
+	Start    time.Time
+	End      time.Time
+	Duration time.Duration
+}
+
+type interval struct {
+	start time.Time
+	end   time.Time
+}
+
+func DefaultWorkingHours() WorkingHours {
+	return WorkingHours{
+		"mon": "09:00-18:00",
+		"tue": "09:00-18:00",
+		"wed": "09:00-18:00",
+		"thu": "09:00-18:00",
+		"fri": "09:00-18:00",
+	}
+}
+
+func DefaultSchedule() Schedule {
+	return Schedule{WorkingHours: DefaultWorkingHours(), Slots: DefaultSlots}
+}
+
+func (w WorkingHours) Validate() error {
+	for day := range w {
+		_, err := w.windows(day, time.Now())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w WorkingHours) windows(day string, date time.Time) ([]interval, error) {
+	if _, ok := weekdays[day]; !ok {
+		return nil, fmt.Errorf(errorWorkDay, day)
+	}
+	var result []interval
+	for _, part := range strings.Split(w[day], ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		from, to, ok := strings.Cut(part, "-")
+		if !ok {
+			return nil, fmt.Errorf(errorWorkHours, part, day)
+		}
+		start, errStart := clockOn(date, from)
+		end, errEnd := clockOn(date, to)
+		if errStart != nil || errEnd != nil || !end.After(start) {
+			return nil, fmt.Errorf(errorWorkHours, part, day)
+		}
+		result = append(result, interval{start: start, end: end})
+	}
+	return result, nil
+}
+
+func clockOn(date time.Time, clock string) (time.Time, error) {
+	t, err := time.Parse(clockPattern, strings.TrimSpace(clock))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Date(date.Year(), date.Month(), date.Day(), t.Hour(), t.Minute(), 0, 0, date.Location()), nil
+}
+
+func dayName(t time.Time) string {
+	for name, day := range weekdays {
+		if day == t.Weekday() {
+			return name
+		}
+	}
+	return ""
+}
+
+func (c *Calendar) busy(from, to time.Time) []interval {
+	d := c.defaultDuration()
+	list := c.lookup(func(idx EventIndex) ([]json.RawMessage, error) {
+		return idx.Overlapping(from.Add(-c.Schedule.BufferAfter), to.Add(c.Schedule.BufferBefore), d)
+	}, func(e *events.Event) bool {
+		return !e.IsTask() && e.StartAt.Add(-c.Schedule.BufferBefore).Before(to) &&
+			e.End(d).Add(c.Schedule.BufferAfter).After(from)
+	})
+	result := make([]interval, 0, len(list))
+	for _, e := range list {
+		result = append(result, interval{
+			start: e.StartAt.Add(-c.Schedule.BufferBefore),
+			end:   e.End(d).Add(c.Schedule.BufferAfter),
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].start.Before(result[j].start)
+	})
+	return result
+}
+
+func (c *Calendar) FreeSlots(duration time.Duration, from, to time.Time) []Slot {
+	limit := c.Schedule.Slots
+	if limit <= 0 {
+		limit = DefaultSlots
+	}
+	busy := c.busy(from, to)
+	loc := c.displayZone()
+	var slots []Slot
+	start := from.In(loc)
+	for day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc); day.Before(to); day = day.AddDate(0, 0, 1) {
+		windows, err := c.Schedule.WorkingHours.windows(dayName(day), day)
+		if err != nil {
+			continue
+		}
+		for _, w := range windows {
+			cursor := roundUp(later(w.start, from))
+			end := earlier(w.end, to)
+			for _, b := range busy {
+				if !b.end.After(cursor) {
+					continue
+				}
+				if !b.start.Before(end) {
+					break
+				}
+				if b.start.Sub(cursor) >= duration {
+					slots = append(slots, Slot{Start: cursor, End: b.start, Duration: duration})
+				}
+				cursor = roundUp(b.end)
+			}
+			if end.Sub(cursor) >= duration {
+				slots = append(slots, Slot{Start: cursor, End: end, Duration: duration})
+			}
+			if len(slots) >= limit {
+				return slots[:limit]
+			}
+		}
+	}
+	return slots
+}
+
+func (c *Calendar) FormatSlots(slots []Slot, duration time.Duration) string {
+	if len(slots) == 0 {
+		return fmt.Sprintf(noFreeMessage, formatDuration(duration))
+	}
+	msgs := []string{fmt.Sprintf(freeHeader, formatDuration(duration))}
+	for i, slot := range slots {
+		msgs = append(msgs, fmt.Sprintf(freeSlotLine, i+1, c.FormatDate(slot.Start),
+			slot.End.In(c.displayZone()).Format(clockPattern), formatDuration(slot.End.Sub(slot.Start))))
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func (c *Calendar) BookSlot(slot Slot, title string, priority events.Priority, details events.Details, force bool) (string, error) {
+	return c.AddEventAt(title, slot.Start, priority, details, AddOptions{
+		End:   slot.Duration.String(),
+		Force: force,
+	})
+}
+
+func roundUp(t time.Time) time.Time {
+	rounded := t.Truncate(slotStep)
+	if rounded.Before(t) {
+		rounded = rounded.Add(slotStep)
+	}
+	return rounded
+}
+
+func later(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func earlier(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	h, m := int(d.Hours()), int(d.Minutes())%60
+	switch {
+	case h == 0:
+		return fmt.Sprintf("%d мин", m)
+	case m == 0:
+		return fmt.Sprintf("%d ч", h)
+	default:
+		return fmt.Sprintf("%d ч %d мин", h, m)
+	}
+}
@@ -0,0 +1,61 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ilsft/Golendar/events"
+)
+
+func TestFreeSlots(t *testing.T) {
+	c := NewCalendar(nil)
+	c.DisplayZone = time.UTC
+	c.Schedule.BufferBefore = 15 * time.Minute
+	c.Schedule.BufferAfter = 15 * time.Minute
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2030, time.March, day, hour, minute, 0, 0, time.UTC)
+	}
+	end := at(4, 14, 30)
+	c.CalendarEvents["a"] = &events.Event{ID: "a", Title: "Планёрка", StartAt: at(4, 10, 0)}
+	c.CalendarEvents["b"] = &events.Event{ID: "b", Title: "Обзор", StartAt: at(4, 13, 0), EndAt: &end}
+
+	slots := c.FreeSlots(time.Hour, at(4, 0, 0), at(5, 0, 0))
+	if len(slots) != 2 {
+		t.Fatalf("Ожидалось 2 окна, получено %v", slots)
+	}
+	if !slots[0].Start.Equal(at(4, 11, 15)) || !slots[0].End.Equal(at(4, 12, 45)) {
+		t.Errorf("Неверное первое окно: %v – %v", slots[0].Start, slots[0].End)
+	}
+	if !slots[1].Start.Equal(at(4, 14, 45)) || !slots[1].End.Equal(at(4, 18, 0)) {
+		t.Errorf("Неверное второе окно: %v – %v", slots[1].Start, slots[1].End)
+	}
+
+	if got := c.FreeSlots(time.Hour, at(9, 0, 0), at(11, 0, 0)); len(got) != 0 {
+		t.Errorf("В выходные рабочих часов нет, получено %v", got)
+	}
+	c.Schedule.WorkingHours["sat"] = "10:00-12:00"
+	if got := c.FreeSlots(time.Hour, at(9, 0, 0), at(11, 0, 0)); len(got) != 1 {
+		t.Errorf("Ожидалось окно в субботу, получено %v", got)
+	}
+	if err := (WorkingHours{"mon": "18:00-09:00"}).Validate(); err == nil {
+		t.Error("Ожидалась ошибка неверных рабочих часов")
+	}
+}
+
+func TestBookSlotKeepsZone(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip(err)
+	}
+	c := NewCalendar(nil)
+	c.DisplayZone = berlin
+	start := time.Date(2030, time.March, 4, 11, 15, 30, 0, berlin)
+	if _, err := c.BookSlot(Slot{Start: start, End: start.Add(2 * time.Hour), Duration: time.Hour}, "Созвон", events.PriorityLow, events.Details{}, false); err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range c.CalendarEvents {
+		if !e.StartAt.Equal(start) || e.TimeZone != "Europe/Berlin" || !e.End(0).Equal(start.Add(time.Hour)) {
+			t.Errorf("Бронирование должно сохранить время и пояс окна: %v %q – %v", e.StartAt, e.TimeZone, e.EndAt)
+		}
+	}
+}
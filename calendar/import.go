@@ -0,0 +1,82 @@
+package calendar
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ilsft/Golendar/events"
+	"github.com/ilsft/Golendar/storage"
+)
+
+const (
+	importMessage        = "Импортировано событий: %d, пропущено: %d"
+	importSkippedMessage = "Пропущено: %s - %v"
+	importExistsMessage  = "событие уже есть в календаре"
+)
+
+func decodeCalendar(data []byte) (*Calendar, error) {
+	data, err := upgradeData(data)
+	if err != nil {
+		return nil, err
+	}
+	other := &Calendar{CalendarEvents: make(map[string]*events.Event)}
+	err = json.Unmarshal(data, other)
+	if err != nil {
+		return nil, fmt.Errorf(errorDeSerialJSON, err)
+	}
+	for _, event := range other.CalendarEvents {
+		event.Restore()
+	}
+	return other, nil
+}
+
+func (c *Calendar) ImportEvents(s storage.Store) (string, error) {
+	data, err := s.Load()
+	if err != nil {
+		return "", err
+	}
+	other, err := decodeCalendar(data)
+	if err != nil {
+		return "", err
+	}
+
+	list := make([]*events.Event, 0, len(other.CalendarEvents))
+	for _, event := range other.CalendarEvents {
+		list = append(list, event)
+	}
+	sortByStart(list)
+	ids := make([]string, 0, len(list))
+	for _, event := range list {
+		ids = append(ids, event.ID)
+	}
+	c.fetch(ids...)
+
+	imported := 0
+	var skipped []string
+	rec := c.track()
+	for _, event := range list {
+		_, exists := c.CalendarEvents[event.ID]
+		if _, trashed := c.Trash[event.ID]; exists || trashed {
+			skipped = append(skipped, fmt.Sprintf(importSkippedMessage, event.Title, importExistsMessage))
+			continue
+		}
+		err = c.Policies.Import.Check(event.StartAt)
+		if err != nil {
+			skipped = append(skipped, fmt.Sprintf(importSkippedMessage, event.Title, err))
+			continue
+		}
+		if event.ReminderDue() {
+			event.Reminder.Rearm(c)
+		}
+		c.touch(rec, event.ID)
+		c.CalendarEvents[event.ID] = event
+		imported++
+	}
+	c.commit(rec, opImport, imported)
+
+	msg := fmt.Sprintf(importMessage, imported, len(skipped))
+	for _, s := range skipped {
+		msg += "\n" + s
+	}
+	return msg, nil
+}
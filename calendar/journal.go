@@ -0,0 +1,238 @@
+package calendar
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ilsft/Golendar/events"
+	"github.com/ilsft/Golendar/logger"
+	"github.com/ilsft/Golendar/storage"
+	validators "github.com/ilsft/Golendar/utils"
+)
+
+const (
+	EventCreated     = "EventCreated"
+	EventEdited      = "EventEdited"
+	EventDeleted     = "EventDeleted"
+	EventRestored    = "EventRestored"
+	EventImported    = "EventImported"
+	EventArchived    = "EventArchived"
+	EventPurged      = "EventPurged"
+	ReminderSet      = "ReminderSet"
+	ReminderRemoved  = "ReminderRemoved"
+	ReminderStopped  = "ReminderStopped"
+	TagsChanged      = "TagsChanged"
+	TaskCreated      = "TaskCreated"
+	TaskCompleted    = "TaskCompleted"
+	TaskReopened     = "TaskReopened"
+	SubtaskChecked   = "SubtaskChecked"
+	TaskCarriedOver  = "TaskCarriedOver"
+	SnapshotRestored = "SnapshotRestored"
+	ChangeUndone     = "ChangeUndone"
+	ChangeRedone     = "ChangeRedone"
+	Baseline         = "Baseline"
+)
+
+const (
+	journalHeader  = "📜 Журнал изменений"
+	journalLine    = "#%d %s %-16s %s %s"
+	replayedHeader = "Состояние после #%d от %s (в корзине: %d)"
+	errorReplay    = "ошибка воспроизведения #%d: %v"
+	errorJournal   = "ошибка журнала %s: %v"
+	errorEmptyLog  = "журнал пуст"
+	errorNoReplay  = "в журнале нет записей до указанного момента"
+	errorNoJournal = "журнал не подключен"
+)
+
+var errNoJournal = errors.New(errorNoJournal)
+
+var opEvents = map[string]string{
+	opAdd:            EventCreated,
+	opDelete:         EventDeleted,
+	opEdit:           EventEdited,
+	opReminderSet:    ReminderSet,
+	opReminderRemove: ReminderRemoved,
+	opReminderStop:   ReminderStopped,
+	opTag:            TagsChanged,
+	opRenameTag:      TagsChanged,
+	opImport:         EventImported,
+	opRestore:        SnapshotRestored,
+	opTrashRestore:   EventRestored,
+	opTrashEmpty:     EventPurged,
+	opAddTask:        TaskCreated,
+	opTaskDone:       TaskCompleted,
+	opTaskReopen:     TaskReopened,
+	opSubtask:        SubtaskChecked,
+	opCarryOver:      TaskCarriedOver,
+}
+
+type DomainEvent struct {
+	Seq     int64           `json:"seq"`
+	Type    string          `json:"type"`
+	At      time.Time       `json:"at"`
+	Actor   string          `json:"actor,omitempty"`
+	EventID string          `json:"event_id"`
+	Title   string          `json:"title,omitempty"`
+	State   json.RawMessage `json:"state"`
+	Trashed *time.Time      `json:"trashed,omitempty"`
+}
+
+type Journal struct {
+	Events  []DomainEvent `json:"events"`
+	Storage storage.Store `json:"-"`
+	Actor   string        `json:"-"`
+	dirty   bool
+}
+
+func NewJournal(s storage.Store) *Journal {
+	return &Journal{Storage: s}
+}
+
+func (j *Journal) Load() error {
+	data, err := j.Storage.Load()
+	if errors.Is(err, os.ErrNotExist) || (err == nil && len(data) == 0) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf(errorJournal, j.Storage.GetFilename(), err)
+	}
+	err = json.Unmarshal(data, j)
+	if err != nil {
+		return fmt.Errorf(errorJournal, j.Storage.GetFilename(), err)
+	}
+	return nil
+}
+
+func (j *Journal) Save() error {
+	if !j.dirty {
+		return nil
+	}
+	data, err := json.Marshal(j)
+	if err != nil {
+		return fmt.Errorf(errorSerialJSON, err)
+	}
+	err = j.Storage.Save(data)
+	if err != nil {
+		return fmt.Errorf(errorJournal, j.Storage.GetFilename(), err)
+	}
+	j.dirty = false
+	return nil
+}
+
+func (j *Journal) record(kind, id string, state json.RawMessage, trashed *time.Time) {
+	var seq int64 = 1
+	if n := len(j.Events); n > 0 {
+		seq = j.Events[n-1].Seq + 1
+	}
+	if len(state) == 0 {
+		state = json.RawMessage("null")
+	}
+	e := DomainEvent{
+		Seq:     seq,
+		Type:    kind,
+		At:      validators.Now(),
+		Actor:   j.Actor,
+		EventID: id,
+		State:   state,
+		Trashed: trashed,
+	}
+	var payload struct {
+		Title string `json:"title"`
+	}
+	if json.Unmarshal(state, &payload) == nil {
+		e.Title = payload.Title
+	}
+	j.Events = append(j.Events, e)
+	j.dirty = true
+}
+
+func (c *Calendar) baseline() {
+	if c.Journal == nil || len(c.Journal.Events) > 0 {
+		return
+	}
+	err := c.loadEvents()
+	if err != nil {
+		logger.Error(err.Error())
+	}
+	for id := range c.CalendarEvents {
+		c.emit(Baseline, id)
+	}
+	for id := range c.Trash {
+		c.emit(Baseline, id)
+	}
+}
+
+func (c *Calendar) emit(kind, id string) {
+	state, trashed := c.snapshot(id)
+	c.journal(kind, id, state, trashed)
+}
+
+func (c *Calendar) journal(kind, id string, state json.RawMessage, trashed *time.Time) {
+	if c.Journal == nil {
+		return
+	}
+	c.Journal.record(kind, id, state, trashed)
+}
+
+func (c *Calendar) ShowJournal(last int) (string, error) {
+	if c.Journal == nil {
+		return "", errNoJournal
+	}
+	list := c.Journal.Events
+	if len(list) == 0 {
+		return "", errors.New(errorEmptyLog)
+	}
+	if last > 0 && last < len(list) {
+		list = list[len(list)-last:]
+	}
+	msgs := []string{journalHeader}
+	for _, e := range list {
+		who := e.EventID
+		if e.Title != "" {
+			who = fmt.Sprintf("%s (%s)", e.Title, e.EventID)
+		}
+		line := fmt.Sprintf(journalLine, e.Seq, c.FormatDate(e.At), e.Type, who, e.Actor)
+		msgs = append(msgs, strings.TrimRight(line, " "))
+	}
+	return strings.Join(msgs, "\n"), nil
+}
+
+func (c *Calendar) Replay(until time.Time, seq int64) (*Calendar, *DomainEvent, error) {
+	if c.Journal == nil {
+		return nil, nil, errNoJournal
+	}
+	state := NewCalendar(nil)
+	state.DisplayZone = c.DisplayZone
+	state.Policies = c.Policies
+	state.DefaultDuration = c.DefaultDuration
+	state.Schedule = c.Schedule
+	var last *DomainEvent
+	for i, e := range c.Journal.Events {
+		if (!until.IsZero() && e.At.After(until)) || (seq > 0 && e.Seq > seq) {
+			break
+		}
+		err := state.applyState(e.EventID, e.State, e.Trashed, false)
+		if err != nil {
+			return nil, nil, fmt.Errorf(errorReplay, e.Seq, err)
+		}
+		last = &c.Journal.Events[i]
+	}
+	if last == nil {
+		return nil, nil, errors.New(errorNoReplay)
+	}
+	return state, last, nil
+}
+
+func (c *Calendar) ShowReplay(state *Calendar, last *DomainEvent) string {
+	list := make([]*events.Event, 0, len(state.CalendarEvents))
+	for _, event := range state.CalendarEvents {
+		list = append(list, event)
+	}
+	sortByStart(list)
+	header := fmt.Sprintf(replayedHeader, last.Seq, c.FormatDate(last.At), len(state.Trash))
+	return header + "\n" + state.FormatEvents(list, ListOptions{})
+}
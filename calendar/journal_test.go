@@ -0,0 +1,70 @@
+package calendar
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ilsft/Golendar/events"
+	"github.com/ilsft/Golendar/storage"
+	validators "github.com/ilsft/Golendar/utils"
+)
+
+func TestJournalReplay(t *testing.T) {
+	defer func() { validators.Now = time.Now }()
+	clock := time.Date(2030, 1, 1, 12, 0, 0, 0, time.Local)
+	validators.Now = func() time.Time { return clock }
+
+	dir := t.TempDir()
+	c := NewCalendar(storage.NewJsonStorage(filepath.Join(dir, "calendar.json")))
+	c.CalendarEvents["a"] = &events.Event{ID: "a", Title: "Турнир", StartAt: clock.Add(48 * time.Hour), Priority: events.PriorityHigh}
+	c.Journal = NewJournal(storage.NewJsonStorage(filepath.Join(dir, "journal.json")))
+	c.Journal.Actor = "ilya"
+	c.baseline()
+
+	clock = clock.Add(time.Hour)
+	title := "Финал"
+	if _, err := c.EditEvent("a", events.Changes{Title: &title}, false); err != nil {
+		t.Fatal(err)
+	}
+	clock = clock.Add(time.Hour)
+	if _, err := c.DeleteEvent("a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := NewJournal(storage.NewJsonStorage(filepath.Join(dir, "journal.json")))
+	if err := loaded.Load(); err != nil {
+		t.Fatal(err)
+	}
+	types := []string{Baseline, EventEdited, EventDeleted}
+	if len(loaded.Events) != len(types) {
+		t.Fatalf("Ожидалось %d записей журнала, получено %d", len(types), len(loaded.Events))
+	}
+	for i, e := range loaded.Events {
+		if e.Seq != int64(i+1) || e.Type != types[i] || e.Actor != "ilya" {
+			t.Errorf("Запись %d: ожидалось #%d %s от ilya, получено %+v", i, i+1, types[i], e)
+		}
+	}
+
+	state, last, err := c.Replay(clock.Add(-90*time.Minute), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last.Seq != 1 || state.CalendarEvents["a"].Title != "Турнир" {
+		t.Errorf("На момент до изменения ожидалось исходное название, получено #%d", last.Seq)
+	}
+	state, _, err = c.Replay(time.Time{}, 2)
+	if err != nil || state.CalendarEvents["a"].Title != "Финал" {
+		t.Fatalf("После #2 ожидалось новое название: %v", err)
+	}
+	state, _, err = c.Replay(time.Time{}, 0)
+	if err != nil || state.CalendarEvents["a"] != nil || state.Trash["a"] == nil {
+		t.Fatalf("В конце журнала событие должно быть в корзине: %v", err)
+	}
+	if _, _, err := c.Replay(clock.Add(-24*time.Hour), 0); err == nil {
+		t.Error("Воспроизведение до начала журнала должно вернуть ошибку")
+	}
+}
@@ -0,0 +1,131 @@
+package calendar
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ilsft/Golendar/storage"
+)
+
+const SchemaVersion = 10
+
+const (
+	migrationMessage = "Файл календаря обновлён с версии %d до %d, резервная копия: %s"
+	backupSuffix     = ".v%d.bak"
+)
+
+var ErrNewerSchema = errors.New("файл календаря создан более новой версией программы")
+
+var (
+	errorNewerSchema      = "%w: версия %d, поддерживается %d"
+	errorMissingMigration = "нет миграции с версии %d"
+	errorBackupMigration  = "не удалось создать резервную копию перед миграцией: %w"
+)
+
+type migration func(doc map[string]json.RawMessage) error
+
+var migrations = map[int]migration{
+	0: migrateV0ToV1,
+	1: addOptionalFields, // time_zone у событий
+	2: addOptionalFields, // description, location и url у событий
+	3: addOptionalFields, // tags у событий
+	4: addOptionalFields, // end_at у событий
+	5: addOptionalFields, // стеки undo/redo и stopped у напоминаний
+	6: addOptionalFields, // корзина trash
+	7: addOptionalFields, // archived_years
+	8: addOptionalFields, // task у событий
+	9: addOptionalFields, // шаблоны templates
+}
+
+func migrateV0ToV1(doc map[string]json.RawMessage) error {
+	if _, ok := doc["events"]; !ok {
+		doc["events"] = json.RawMessage("{}")
+	}
+	return nil
+}
+
+// addOptionalFields covers versions that only add fields with empty
+// defaults: older documents load as is, but older binaries must refuse
+// the new version instead of dropping the fields on save.
+func addOptionalFields(map[string]json.RawMessage) error {
+	return nil
+}
+
+func documentVersion(doc map[string]json.RawMessage) (int, error) {
+	raw, ok := doc["version"]
+	if !ok {
+		return 0, nil
+	}
+	var version int
+	err := json.Unmarshal(raw, &version)
+	if err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+func decodeDocument(data []byte) (map[string]json.RawMessage, int, error) {
+	var doc map[string]json.RawMessage
+	err := json.Unmarshal(data, &doc)
+	if err != nil {
+		return nil, 0, fmt.Errorf(errorDeSerialJSON, err)
+	}
+	version, err := documentVersion(doc)
+	if err != nil {
+		return nil, 0, fmt.Errorf(errorDeSerialJSON, err)
+	}
+	if version > SchemaVersion {
+		return nil, 0, fmt.Errorf(errorNewerSchema, ErrNewerSchema, version, SchemaVersion)
+	}
+	return doc, version, nil
+}
+
+func upgrade(doc map[string]json.RawMessage, version int) ([]byte, error) {
+	for ; version < SchemaVersion; version++ {
+		step, ok := migrations[version]
+		if !ok {
+			return nil, fmt.Errorf(errorMissingMigration, version)
+		}
+		err := step(doc)
+		if err != nil {
+			return nil, err
+		}
+	}
+	doc["version"], _ = json.Marshal(SchemaVersion)
+	return json.Marshal(doc)
+}
+
+func upgradeData(data []byte) ([]byte, error) {
+	doc, version, err := decodeDocument(data)
+	if err != nil {
+		return nil, err
+	}
+	if version == SchemaVersion {
+		return data, nil
+	}
+	return upgrade(doc, version)
+}
+
+func (c *Calendar) migrate(data []byte) ([]byte, error) {
+	doc, version, err := decodeDocument(data)
+	if err != nil {
+		return nil, err
+	}
+	if version == SchemaVersion {
+		return data, nil
+	}
+
+	backupName := c.Storage.GetFilename() + fmt.Sprintf(backupSuffix, version)
+	err = storage.NewJsonStorage(backupName).Save(data)
+	if err != nil {
+		return nil, fmt.Errorf(errorBackupMigration, err)
+	}
+
+	data, err = upgrade(doc, version)
+	if err != nil {
+		return nil, err
+	}
+	c.Notify(fmt.Sprintf(migrationMessage, version, SchemaVersion, backupName))
+	return data, nil
+}
@@ -0,0 +1,53 @@
+package calendar
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ilsft/Golendar/storage"
+)
+
+func TestLoadMigratesUnversionedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "calendar.json")
+	legacy := `{"events":{"1":{"id":"1","title":"встреча","start_at":"2030-01-01T10:00:00Z","priority":"low","reminder":null}}}`
+	os.WriteFile(path, []byte(legacy), 0644)
+
+	c := NewCalendar(storage.NewJsonStorage(path))
+	if err := c.Load(); err != nil {
+		t.Fatalf("Ошибка загрузки: %v", err)
+	}
+	if c.Version != SchemaVersion || len(c.CalendarEvents) != 1 {
+		t.Errorf("Миграция не выполнена: версия %d, событий %d", c.Version, len(c.CalendarEvents))
+	}
+	backup, err := os.ReadFile(path + ".v0.bak")
+	if err != nil || string(backup) != legacy {
+		t.Errorf("Резервная копия не создана: %v", err)
+	}
+}
+
+func TestLoadRefusesNewerVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "calendar.json")
+	os.WriteFile(path, []byte(`{"version":999,"events":{}}`), 0644)
+
+	c := NewCalendar(storage.NewJsonStorage(path))
+	if err := c.Load(); !errors.Is(err, ErrNewerSchema) {
+		t.Errorf("Ожидалась ошибка новой версии, получено: %v", err)
+	}
+}
+
+func TestEveryVersionHasMigration(t *testing.T) {
+	for version := 0; version < SchemaVersion; version++ {
+		if _, ok := migrations[version]; !ok {
+			t.Errorf("Нет миграции с версии %d до %d", version, version+1)
+		}
+	}
+	data, err := upgradeData([]byte(`{"version":1,"events":{}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, version, _ := decodeDocument(data); version != SchemaVersion {
+		t.Errorf("Документ версии 1 должен обновиться до %d, получено %d", SchemaVersion, version)
+	}
+}
@@ -0,0 +1,194 @@
+package calendar
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ilsft/Golendar/events"
+	"github.com/ilsft/Golendar/logger"
+	"github.com/ilsft/Golendar/storage"
+	validators "github.com/ilsft/Golendar/utils"
+)
+
+type EventIndex interface {
+	storage.Store
+	Meta() ([]byte, error)
+	All() ([]json.RawMessage, error)
+	Get(ids ...string) ([]json.RawMessage, error)
+	Range(from time.Time, to time.Time) ([]json.RawMessage, error)
+	Overlapping(from time.Time, to time.Time, d time.Duration) ([]json.RawMessage, error)
+	TitlePrefix(prefix string) ([]json.RawMessage, error)
+	IDPrefix(prefix string) ([]json.RawMessage, error)
+	ByPriority(priority string) ([]json.RawMessage, error)
+	OpenTasks() ([]json.RawMessage, error)
+	Len() (int, error)
+}
+
+func (c *Calendar) index() (EventIndex, bool) {
+	return storage.As[EventIndex](c.Storage)
+}
+
+func (c *Calendar) EventsInRange(from time.Time, to time.Time) ([]*events.Event, error) {
+	return c.query(func(idx EventIndex) ([]json.RawMessage, error) {
+		return idx.Range(from, to)
+	}, func(e *events.Event) bool {
+		return !e.StartAt.Before(from) && !e.StartAt.After(to)
+	})
+}
+
+func (c *Calendar) EventsByTitlePrefix(prefix string) ([]*events.Event, error) {
+	return c.query(func(idx EventIndex) ([]json.RawMessage, error) {
+		return idx.TitlePrefix(prefix)
+	}, func(e *events.Event) bool {
+		return validators.HasWordPrefix(e.Title, prefix)
+	})
+}
+
+func (c *Calendar) EventsByIDPrefix(prefix string) []*events.Event {
+	prefix = strings.ToLower(prefix)
+	return c.lookup(func(idx EventIndex) ([]json.RawMessage, error) {
+		return idx.IDPrefix(prefix)
+	}, func(e *events.Event) bool {
+		return strings.HasPrefix(e.ID, prefix)
+	})
+}
+
+func (c *Calendar) EventsByPriority(priority events.Priority) ([]*events.Event, error) {
+	if p, err := events.ParsePriority(string(priority)); err == nil {
+		priority = p
+	}
+	return c.query(func(idx EventIndex) ([]json.RawMessage, error) {
+		return idx.ByPriority(string(priority))
+	}, func(e *events.Event) bool {
+		return e.Priority == priority
+	})
+}
+
+// query reads the candidates of a lookup from the index while the events
+// are not loaded yet and then matches everything in memory, so unsaved
+// changes are taken into account. Without an index it is a plain scan.
+func (c *Calendar) query(find func(idx EventIndex) ([]json.RawMessage, error), match func(e *events.Event) bool) ([]*events.Event, error) {
+	idx, ok := c.index()
+	if !ok || c.complete {
+		return c.scan(match), nil
+	}
+	_, err := c.resolve(find(idx))
+	if err != nil {
+		return nil, err
+	}
+	return c.filter(match), nil
+}
+
+// lookup is query for callers that report nothing but the events: a
+// broken index is logged and the events in memory are used.
+func (c *Calendar) lookup(find func(idx EventIndex) ([]json.RawMessage, error), match func(e *events.Event) bool) []*events.Event {
+	list, err := c.query(find, match)
+	if err != nil {
+		logger.Error(err.Error())
+		return c.filter(match)
+	}
+	return list
+}
+
+// fetch reads the given events from the index unless they are in memory
+// already or were removed since they were read.
+func (c *Calendar) fetch(ids ...string) {
+	idx, ok := c.index()
+	if !ok || c.complete {
+		return
+	}
+	var missing []string
+	for _, id := range ids {
+		if _, ok := c.CalendarEvents[id]; !ok && !c.known[id] {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) == 0 {
+		return
+	}
+	_, err := c.resolve(idx.Get(missing...))
+	if err != nil {
+		logger.Error(err.Error())
+	}
+}
+
+// loadEvents reads the events that are still only in the index.
+func (c *Calendar) loadEvents() error {
+	if c.complete {
+		return nil
+	}
+	if idx, ok := c.index(); ok {
+		_, err := c.resolve(idx.All())
+		if err != nil {
+			return err
+		}
+	}
+	c.complete = true
+	return nil
+}
+
+func (c *Calendar) resolve(raw []json.RawMessage, err error) ([]*events.Event, error) {
+	if err != nil {
+		return nil, err
+	}
+	if c.known == nil {
+		c.known = make(map[string]bool)
+	}
+	result := make([]*events.Event, 0, len(raw))
+	for _, r := range raw {
+		var e events.Event
+		err = json.Unmarshal(r, &e)
+		if err != nil {
+			return nil, fmt.Errorf(errorDeSerialJSON, err)
+		}
+		if loaded, ok := c.CalendarEvents[e.ID]; ok {
+			result = append(result, loaded)
+			continue
+		}
+		if c.known[e.ID] {
+			continue
+		}
+		e.Restore()
+		c.CalendarEvents[e.ID] = &e
+		c.known[e.ID] = true
+		result = append(result, &e)
+	}
+	return result, nil
+}
+
+func (c *Calendar) scan(match func(e *events.Event) bool) []*events.Event {
+	err := c.loadEvents()
+	if err != nil {
+		logger.Error(err.Error())
+	}
+	return c.filter(match)
+}
+
+func (c *Calendar) filter(match func(e *events.Event) bool) []*events.Event {
+	var result []*events.Event
+	for _, e := range c.CalendarEvents {
+		if match(e) {
+			result = append(result, e)
+		}
+	}
+	sortByStart(result)
+	return result
+}
+
+func sortByStart(list []*events.Event) {
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].StartAt.Equal(list[j].StartAt) {
+			return list[i].ID < list[j].ID
+		}
+		return list[i].StartAt.Before(list[j].StartAt)
+	})
+}
+
+func sortByPriority(list []*events.Event) {
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Priority.Rank() > list[j].Priority.Rank()
+	})
+}
@@ -0,0 +1,155 @@
+package calendar
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ilsft/Golendar/events"
+	"github.com/ilsft/Golendar/storage"
+)
+
+var benchStart = time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)
+
+func seedCalendar(tb testing.TB, s storage.Store, n int) {
+	tb.Helper()
+	c := NewCalendar(s)
+	priorities := []events.Priority{events.PriorityLow, events.PriorityMedium, events.PriorityHigh}
+	for i := 0; i < n; i++ {
+		id := fmt.Sprintf("id-%06d", i)
+		c.CalendarEvents[id] = &events.Event{
+			ID:       id,
+			Title:    fmt.Sprintf("Встреча %d с командой", i),
+			StartAt:  benchStart.Add(time.Duration(i) * time.Hour),
+			Priority: priorities[i%len(priorities)],
+		}
+	}
+	if err := c.Save(); err != nil {
+		tb.Fatal(err)
+	}
+}
+
+func TestIndexQueriesWithoutLoad(t *testing.T) {
+	s := storage.NewIndexStorage(filepath.Join(t.TempDir(), "calendar"))
+	seedCalendar(t, s, 100)
+
+	c := NewCalendar(storage.NewIndexStorage(s.GetFilename()))
+	inRange, err := c.EventsInRange(benchStart, benchStart.Add(9*time.Hour))
+	if err != nil || len(inRange) != 10 {
+		t.Fatalf("Ожидалось 10 событий в диапазоне, получено %d: %v", len(inRange), err)
+	}
+	byTitle, err := c.EventsByTitlePrefix("коман")
+	if err != nil || len(byTitle) != 100 {
+		t.Errorf("Ожидалось 100 событий по слову, получено %d: %v", len(byTitle), err)
+	}
+	byPriority, err := c.EventsByPriority(events.PriorityHigh)
+	if err != nil || len(byPriority) != 33 {
+		t.Errorf("Ожидалось 33 события с высоким приоритетом, получено %d: %v", len(byPriority), err)
+	}
+
+	data, err := c.Storage.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Version int                        `json:"version"`
+		Events  map[string]json.RawMessage `json:"events"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil || doc.Version != SchemaVersion || len(doc.Events) != 100 {
+		t.Errorf("Полная загрузка из индекса неверна: %d событий, версия %d, %v", len(doc.Events), doc.Version, err)
+	}
+}
+
+func TestLazyLoadSavesChangedEvents(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "calendar")
+	seedCalendar(t, storage.NewIndexStorage(dir), 100)
+	dataFile := filepath.Join(dir, "events-1.dat")
+	before, err := os.Stat(dataFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewCalendar(storage.NewIndexStorage(dir))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.CalendarEvents) != 0 {
+		t.Fatalf("События не должны читаться при загрузке, прочитано %d", len(c.CalendarEvents))
+	}
+	list, err := c.ListEvents(ListOptions{From: benchStart, To: benchStart.Add(9 * time.Hour), Priority: events.PriorityHigh})
+	if err != nil || len(list) != 3 {
+		t.Fatalf("Ожидалось 3 события, получено %d: %v", len(list), err)
+	}
+	if len(c.CalendarEvents) != 10 {
+		t.Errorf("Должны читаться только события из диапазона, прочитано %d", len(c.CalendarEvents))
+	}
+	title := "Итоги недели"
+	if _, err := c.EditEvent("id-000000", events.Changes{Title: &title}, true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.DeleteEvent("id-000050"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+	after, err := os.Stat(dataFile)
+	if err != nil {
+		t.Fatalf("Файл данных должен дописываться, а не создаваться заново: %v", err)
+	}
+	if grown := after.Size() - before.Size(); grown <= 0 || grown > 1024 {
+		t.Errorf("Должно дописаться одно событие, файл вырос на %d байт", grown)
+	}
+
+	reopened := NewCalendar(storage.NewIndexStorage(dir))
+	if err := reopened.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := reopened.EventsByTitlePrefix("итоги"); err != nil || len(got) != 1 {
+		t.Errorf("Изменённое событие не сохранилось: %v, %v", got, err)
+	}
+	if _, err := reopened.GetEventByID("id-000050"); err == nil {
+		t.Error("Удалённое событие не должно оставаться в индексе")
+	}
+	if _, ok := reopened.Trash["id-000050"]; !ok {
+		t.Error("Удалённое событие должно быть в корзине")
+	}
+	if got := len(reopened.EventsByIDPrefix("id-")); got != 99 {
+		t.Errorf("Ожидалось 99 событий, получено %d", got)
+	}
+}
+
+const benchEvents = 20000
+
+func BenchmarkLoadJsonStorage(b *testing.B) {
+	s := storage.NewJsonStorage(filepath.Join(b.TempDir(), "calendar.json"))
+	seedCalendar(b, s, benchEvents)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c := NewCalendar(s)
+		if err := c.Load(); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := c.EventsInRange(benchStart, benchStart.Add(24*time.Hour)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkLoadIndexStorage(b *testing.B) {
+	dir := filepath.Join(b.TempDir(), "calendar")
+	seedCalendar(b, storage.NewIndexStorage(dir), benchEvents)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c := NewCalendar(storage.NewIndexStorage(dir))
+		if err := c.Load(); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := c.EventsInRange(benchStart, benchStart.Add(24*time.Hour)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
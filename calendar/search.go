@@ -0,0 +1,305 @@
+package calendar
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/ilsft/Golendar/events"
+	"github.com/ilsft/Golendar/logger"
+	validators "github.com/ilsft/Golendar/utils"
+)
+
+type SearchMode string
+
+const (
+	SearchAll       SearchMode = "all"
+	SearchSubstring SearchMode = "substring"
+	SearchWord      SearchMode = "word"
+	SearchFuzzy     SearchMode = "fuzzy"
+)
+
+const (
+	scoreWord      = 4
+	scoreSubstring = 2
+	scoreFuzzy     = 1
+	titleWeight    = 3
+
+	highlightStart = "\033[1;33m"
+	highlightEnd   = "\033[0m"
+)
+
+const (
+	fieldTitle       = "title"
+	fieldDescription = "description"
+	fieldLocation    = "location"
+	fieldReminder    = "reminder"
+)
+
+var fieldNames = map[string]string{
+	fieldTitle:       "Название",
+	fieldDescription: "Описание",
+	fieldLocation:    "Место",
+	fieldReminder:    "Напоминание",
+}
+
+var (
+	errorEmptyQuery    = "пустой поисковый запрос"
+	errorSearchMode    = "неизвестный режим поиска: %s"
+	errorNothingFound  = "ничего не найдено по запросу: %s"
+	searchResultHeader = "%d. %s - %s - %s (релевантность %d)"
+	searchFieldLine    = "   %s: %s"
+)
+
+type SearchMatch struct {
+	Field string
+	Text  string
+	Spans [][2]int
+}
+
+type SearchResult struct {
+	Event   *events.Event
+	Score   int
+	Matches []SearchMatch
+}
+
+type span struct {
+	start int
+	end   int
+	score int
+}
+
+func ParseSearchMode(s string) (SearchMode, error) {
+	switch mode := SearchMode(strings.ToLower(s)); mode {
+	case "", SearchAll:
+		return SearchAll, nil
+	case SearchSubstring, SearchWord, SearchFuzzy:
+		return mode, nil
+	default:
+		return "", fmt.Errorf(errorSearchMode, s)
+	}
+}
+
+func (c *Calendar) Search(query string, mode SearchMode) []SearchResult {
+	err := c.loadEvents()
+	if err != nil {
+		logger.Error(err.Error())
+	}
+	list := make([]*events.Event, 0, len(c.CalendarEvents))
+	for _, event := range c.CalendarEvents {
+		list = append(list, event)
+	}
+	return searchEvents(list, query, mode)
+}
+
+func searchEvents(list []*events.Event, query string, mode SearchMode) []SearchResult {
+	terms := validators.Tokenize(query)
+	if len(terms) == 0 {
+		return nil
+	}
+	var results []SearchResult
+	for _, event := range list {
+		if r, ok := searchEvent(event, terms, mode); ok {
+			results = append(results, r)
+		}
+	}
+	sortResults(results)
+	return results
+}
+
+func sortResults(results []SearchResult) {
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Event.StartAt.Before(results[j].Event.StartAt)
+	})
+}
+
+func (c *Calendar) ShowSearch(query string, mode SearchMode) (string, error) {
+	if len(validators.Tokenize(query)) == 0 {
+		return "", errors.New(errorEmptyQuery)
+	}
+	results := c.Search(query, mode)
+	if len(c.ArchivedYears) > 0 {
+		archived, err := c.ArchivedEvents()
+		if err != nil {
+			return "", err
+		}
+		results = append(results, searchEvents(archived, query, mode)...)
+		sortResults(results)
+	}
+	if len(results) == 0 {
+		return "", fmt.Errorf(errorNothingFound, query)
+	}
+	var lines []string
+	for i, r := range results {
+		header := fmt.Sprintf(searchResultHeader, i+1, r.Event.Title,
+			c.FormatDate(r.Event.StartAt), r.Event.Priority, r.Score)
+		if c.isArchived(r.Event) {
+			header += archivedMark
+		}
+		lines = append(lines, header)
+		for _, m := range r.Matches {
+			lines = append(lines, fmt.Sprintf(searchFieldLine, fieldNames[m.Field], Highlight(m.Text, m.Spans)))
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func searchEvent(event *events.Event, terms []string, mode SearchMode) (SearchResult, bool) {
+	fields := []struct {
+		name string
+		text string
+	}{
+		{fieldTitle, event.Title},
+		{fieldDescription, event.Description},
+		{fieldLocation, event.Location},
+	}
+	if event.Reminder != nil {
+		fields = append(fields, struct {
+			name string
+			text string
+		}{fieldReminder, event.Reminder.Message})
+	}
+
+	result := SearchResult{Event: event}
+	found := make([]bool, len(terms))
+	for _, f := range fields {
+		if f.text == "" {
+			continue
+		}
+		text := []rune(f.text)
+		lower := lowerRunes(text)
+		var spans [][2]int
+		for i, term := range terms {
+			best, ok := matchTerm(lower, []rune(term), mode)
+			if !ok {
+				continue
+			}
+			found[i] = true
+			weight := 1
+			if f.name == fieldTitle {
+				weight = titleWeight
+			}
+			result.Score += best.score * weight
+			spans = append(spans, [2]int{best.start, best.end})
+		}
+		if len(spans) > 0 {
+			result.Matches = append(result.Matches, SearchMatch{Field: f.name, Text: f.text, Spans: spans})
+		}
+	}
+	for _, ok := range found {
+		if !ok {
+			return SearchResult{}, false
+		}
+	}
+	return result, true
+}
+
+func matchTerm(text []rune, term []rune, mode SearchMode) (span, bool) {
+	var best span
+	ok := false
+	consider := func(s span) {
+		if !ok || s.score > best.score {
+			best = s
+			ok = true
+		}
+	}
+	for _, w := range words(text) {
+		word := text[w.start:w.end]
+		switch {
+		case mode != SearchSubstring && equalRunes(word, term):
+			consider(span{w.start, w.end, scoreWord})
+		case (mode == SearchAll || mode == SearchFuzzy) && fuzzyMatch(word, term):
+			consider(span{w.start, w.end, scoreFuzzy})
+		}
+	}
+	if mode == SearchAll || mode == SearchSubstring {
+		if i := indexRunes(text, term); i >= 0 {
+			consider(span{i, i + len(term), scoreSubstring})
+		}
+	}
+	return best, ok
+}
+
+func fuzzyMatch(word []rune, term []rune) bool {
+	allowed := 1
+	if len(term) > 5 {
+		allowed = 2
+	}
+	if len(term) < 3 {
+		return false
+	}
+	return validators.Levenshtein(word, term) <= allowed
+}
+
+func words(text []rune) []span {
+	var result []span
+	start := -1
+	for i, r := range text {
+		isWord := unicode.IsLetter(r) || unicode.IsNumber(r)
+		switch {
+		case isWord && start < 0:
+			start = i
+		case !isWord && start >= 0:
+			result = append(result, span{start: start, end: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		result = append(result, span{start: start, end: len(text)})
+	}
+	return result
+}
+
+func lowerRunes(text []rune) []rune {
+	lower := make([]rune, len(text))
+	for i, r := range text {
+		lower[i] = unicode.ToLower(r)
+	}
+	return lower
+}
+
+func equalRunes(a []rune, b []rune) bool {
+	return string(a) == string(b)
+}
+
+func indexRunes(text []rune, term []rune) int {
+	for i := 0; i+len(term) <= len(text); i++ {
+		if equalRunes(text[i:i+len(term)], term) {
+			return i
+		}
+	}
+	return -1
+}
+
+func Highlight(text string, spans [][2]int) string {
+	if !events.ColorEnabled() {
+		return text
+	}
+	runes := []rune(text)
+	marks := make([]int, len(runes)+1)
+	for _, s := range spans {
+		marks[s[0]]++
+		marks[s[1]]--
+	}
+	var b strings.Builder
+	depth := 0
+	for i := 0; i <= len(runes); i++ {
+		next := depth + marks[i]
+		if depth == 0 && next > 0 {
+			b.WriteString(highlightStart)
+		}
+		if depth > 0 && next == 0 {
+			b.WriteString(highlightEnd)
+		}
+		depth = next
+		if i < len(runes) {
+			b.WriteRune(runes[i])
+		}
+	}
+	return b.String()
+}
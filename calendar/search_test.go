@@ -0,0 +1,46 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ilsft/Golendar/events"
+	"github.com/ilsft/Golendar/reminder"
+)
+
+func TestSearchModesAndRanking(t *testing.T) {
+	c := NewCalendar(nil)
+	base := time.Date(2030, 1, 1, 10, 0, 0, 0, time.UTC)
+	c.CalendarEvents["a"] = &events.Event{ID: "a", Title: "Встреча с клиентом", StartAt: base.Add(time.Hour)}
+	c.CalendarEvents["b"] = &events.Event{ID: "b", Title: "Обед", StartAt: base,
+		Description: "обсудить договор, клиент ждёт"}
+	c.CalendarEvents["c"] = &events.Event{ID: "c", Title: "Спорт", StartAt: base,
+		Reminder: &reminder.Reminder{Message: "позвонить клиенту"}}
+
+	results := c.Search("клиент", SearchAll)
+	if len(results) != 3 || results[0].Event.ID != "a" {
+		t.Fatalf("Совпадение в названии должно быть первым: %+v", results)
+	}
+	if got := c.Search("клиент", SearchWord); len(got) != 1 || got[0].Event.ID != "b" {
+		t.Errorf("Поиск по слову должен найти только описание: %+v", got)
+	}
+	if got := c.Search("клеинтом", SearchFuzzy); len(got) != 1 || got[0].Event.ID != "a" {
+		t.Errorf("Нечёткий поиск должен найти опечатку: %+v", got)
+	}
+	if got := c.Search("клиент договор", SearchAll); len(got) != 1 || got[0].Event.ID != "b" {
+		t.Errorf("Все слова запроса должны совпасть: %+v", got)
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	got := Highlight("Встреча с клиентом", [][2]int{{10, 16}})
+	want := "Встреча с " + highlightStart + "клиент" + highlightEnd + "ом"
+	if got != want {
+		t.Errorf("Ожидалось %q, получено %q", want, got)
+	}
+	events.SetColor(false)
+	defer events.SetColor(true)
+	if got := Highlight("Встреча с клиентом", [][2]int{{10, 16}}); got != "Встреча с клиентом" {
+		t.Errorf("Без терминала совпадения не подсвечиваются, получено %q", got)
+	}
+}
@@ -0,0 +1,173 @@
+package calendar
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ilsft/Golendar/events"
+	"github.com/ilsft/Golendar/logger"
+)
+
+const (
+	tagsAddedMessage   = "Событие: %s, добавлены теги: %s"
+	tagsRemovedMessage = "Событие: %s, удалены теги: %s"
+	tagRenamedMessage  = "Тег %s переименован в %s в событиях: %d"
+	tagCountLine       = "#%s - %d"
+)
+
+var (
+	errorNoTagsChanged = "теги не изменились"
+	errorNoTags        = "тегов нет"
+	errorTagNotFound   = "тег %s не найден"
+)
+
+type TagFilter struct {
+	AllOf  [][]string
+	NoneOf []string
+}
+
+func ParseTagFilter(include []string, exclude []string) (TagFilter, error) {
+	var f TagFilter
+	for _, group := range include {
+		tags, err := events.NormalizeTags(strings.Split(group, ","))
+		if err != nil {
+			return TagFilter{}, err
+		}
+		f.AllOf = append(f.AllOf, tags)
+	}
+	for _, group := range exclude {
+		tags, err := events.NormalizeTags(strings.Split(group, ","))
+		if err != nil {
+			return TagFilter{}, err
+		}
+		f.NoneOf = append(f.NoneOf, tags...)
+	}
+	return f, nil
+}
+
+func (f TagFilter) Empty() bool {
+	return len(f.AllOf) == 0 && len(f.NoneOf) == 0
+}
+
+func (f TagFilter) Match(e *events.Event) bool {
+	for _, tag := range f.NoneOf {
+		if e.HasTag(tag) {
+			return false
+		}
+	}
+	for _, anyOf := range f.AllOf {
+		matched := false
+		for _, tag := range anyOf {
+			if e.HasTag(tag) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Calendar) TagEvent(id string, tags []string) (string, error) {
+	event, err := c.GetEventByID(id)
+	if err != nil {
+		return "", err
+	}
+	rec := c.track(id)
+	added, err := event.AddTags(tags...)
+	if err != nil {
+		return "", err
+	}
+	c.commit(rec, opTag, event.Title)
+	if len(added) == 0 {
+		return "", errors.New(errorNoTagsChanged)
+	}
+	return fmt.Sprintf(tagsAddedMessage, event.Title, strings.Join(added, ", ")), nil
+}
+
+func (c *Calendar) UntagEvent(id string, tags []string) (string, error) {
+	event, err := c.GetEventByID(id)
+	if err != nil {
+		return "", err
+	}
+	rec := c.track(id)
+	removed, err := event.RemoveTags(tags...)
+	if err != nil {
+		return "", err
+	}
+	c.commit(rec, opTag, event.Title)
+	if len(removed) == 0 {
+		return "", errors.New(errorNoTagsChanged)
+	}
+	return fmt.Sprintf(tagsRemovedMessage, event.Title, strings.Join(removed, ", ")), nil
+}
+
+func (c *Calendar) RenameTag(oldTag string, newTag string) (string, error) {
+	oldTag, err := events.NormalizeTag(oldTag)
+	if err != nil {
+		return "", err
+	}
+	newTag, err = events.NormalizeTag(newTag)
+	if err != nil {
+		return "", err
+	}
+	err = c.loadEvents()
+	if err != nil {
+		return "", err
+	}
+	renamed := 0
+	rec := c.track()
+	for id, event := range c.CalendarEvents {
+		if event.HasTag(oldTag) {
+			c.touch(rec, id)
+		}
+		if event.RenameTag(oldTag, newTag) {
+			renamed++
+		}
+	}
+	c.commit(rec, opRenameTag, oldTag)
+	if renamed == 0 {
+		return "", fmt.Errorf(errorTagNotFound, oldTag)
+	}
+	return fmt.Sprintf(tagRenamedMessage, oldTag, newTag, renamed), nil
+}
+
+func (c *Calendar) TagCounts() map[string]int {
+	err := c.loadEvents()
+	if err != nil {
+		logger.Error(err.Error())
+	}
+	counts := make(map[string]int)
+	for _, event := range c.CalendarEvents {
+		for _, tag := range event.Tags {
+			counts[tag]++
+		}
+	}
+	return counts
+}
+
+func (c *Calendar) Tags() []string {
+	counts := c.TagCounts()
+	tags := make([]string, 0, len(counts))
+	for tag := range counts {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+func (c *Calendar) ShowTags() (string, error) {
+	counts := c.TagCounts()
+	if len(counts) == 0 {
+		return "", errors.New(errorNoTags)
+	}
+	var lines []string
+	for _, tag := range c.Tags() {
+		lines = append(lines, fmt.Sprintf(tagCountLine, tag, counts[tag]))
+	}
+	return strings.Join(lines, "\n"), nil
+}
@@ -0,0 +1,40 @@
+package calendar
+
+import (
+	"testing"
+
+	"github.com/ilsft/Golendar/events"
+)
+
+func TestTagFilterAndRename(t *testing.T) {
+	c := NewCalendar(nil)
+	add := func(id string, tags ...string) {
+		e := &events.Event{ID: id, Title: id}
+		if _, err := e.AddTags(tags...); err != nil {
+			t.Fatal(err)
+		}
+		c.CalendarEvents[id] = e
+	}
+	add("a", "work", "client:acme")
+	add("b", "work", "personal")
+	add("c", "personal")
+
+	f, err := ParseTagFilter([]string{"work", "client:acme,personal"}, []string{"#Personal"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	matched := c.scan(f.Match)
+	if len(matched) != 1 || matched[0].ID != "a" {
+		t.Errorf("Ожидалось только событие a, получено %v", matched)
+	}
+
+	if _, err := c.RenameTag("personal", "home"); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Tags(); len(got) != 3 || got[2] != "work" || c.TagCounts()["home"] != 2 {
+		t.Errorf("Переименование не применилось ко всем событиям: %v", got)
+	}
+	if _, err := ParseTagFilter([]string{"bad tag"}, nil); err == nil {
+		t.Error("Ожидалась ошибка недопустимого тега")
+	}
+}
@@ -0,0 +1,209 @@
+package calendar
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ilsft/Golendar/events"
+	validators "github.com/ilsft/Golendar/utils"
+)
+
+const (
+	taskAddedMessage    = "Задача: %s добавлена, срок %s"
+	taskDoneMessage     = "Задача: %s выполнена"
+	taskReminderStopped = "\nНапоминание остановлено"
+	taskReopenedMessage = "Задача: %s снова в работе"
+	subtaskMessage      = "Задача: %s, подзадачи %s"
+	carriedMessage      = "Перенесено просроченных задач: %d"
+	carriedLine         = "  %s → %s"
+	noCarryMessage      = "Просроченных задач нет"
+	overdueMessage      = "Просроченных задач: %d, перенести на ближайший день: todo carry"
+	noTasksMessage      = "задач нет"
+	taskLine            = "#%d %s %s - %s - срок %s - %s"
+	subtaskLine         = "      %s %s"
+	markTodo            = "☐"
+	markDone            = "✅"
+	markOverdue         = "⚠️"
+	opAddTask           = "добавление задачи %s"
+	opTaskDone          = "выполнение задачи %s"
+	opTaskReopen        = "возобновление задачи %s"
+	opSubtask           = "подзадачи задачи %s"
+	opCarryOver         = "перенос задач: %d"
+)
+
+func (c *Calendar) CompleteTask(id string) (string, error) {
+	event, err := c.GetEventByID(id)
+	if err != nil {
+		return "", err
+	}
+	rec := c.track(id)
+	pending := event.ReminderDue() && !event.Reminder.Sent
+	err = event.Complete(validators.Now())
+	if err != nil {
+		return "", err
+	}
+	c.commit(rec, opTaskDone, event.Title)
+	msg := fmt.Sprintf(taskDoneMessage, event.Title)
+	if pending {
+		msg += taskReminderStopped
+	}
+	return msg, nil
+}
+
+func (c *Calendar) ReopenTask(id string) (string, error) {
+	event, err := c.GetEventByID(id)
+	if err != nil {
+		return "", err
+	}
+	rec := c.track(id)
+	err = event.Reopen()
+	if err != nil {
+		return "", err
+	}
+	if event.ReminderDue() {
+		event.Reminder.Rearm(c)
+	}
+	c.commit(rec, opTaskReopen, event.Title)
+	return fmt.Sprintf(taskReopenedMessage, event.Title), nil
+}
+
+func (c *Calendar) CheckSubtask(id string, n int, done bool) (string, error) {
+	event, err := c.GetEventByID(id)
+	if err != nil {
+		return "", err
+	}
+	rec := c.track(id)
+	err = event.CheckSubtask(n, done)
+	if err != nil {
+		return "", err
+	}
+	c.commit(rec, opSubtask, event.Title)
+	return fmt.Sprintf(subtaskMessage, event.Title, event.Progress()), nil
+}
+
+func (c *Calendar) Tasks(all bool) []*events.Event {
+	now := validators.Now()
+	match := func(e *events.Event) bool {
+		return e.IsTask() && (all || !e.Task.Done)
+	}
+	var list []*events.Event
+	if all {
+		list = c.scan(match)
+	} else {
+		list = c.lookup(func(idx EventIndex) ([]json.RawMessage, error) {
+			return idx.OpenTasks()
+		}, match)
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		return taskOrder(list[i], now) < taskOrder(list[j], now)
+	})
+	return list
+}
+
+func taskOrder(e *events.Event, now time.Time) int {
+	switch {
+	case e.Overdue(now):
+		return 0
+	case !e.Task.Done:
+		return 1
+	default:
+		return 2
+	}
+}
+
+func (c *Calendar) FormatTasks(list []*events.Event) string {
+	if len(list) == 0 {
+		return noTasksMessage
+	}
+	now := validators.Now()
+	var msgs []string
+	for i, e := range list {
+		mark := markTodo
+		switch {
+		case e.Task.Done:
+			mark = markDone
+		case e.Overdue(now):
+			mark = markOverdue
+		}
+		msg := fmt.Sprintf(taskLine, i+1, mark, e.ID, e.Title, c.FormatDate(e.StartAt), e.Priority.Colorize())
+		if progress := e.Progress(); progress != "" {
+			msg += " - " + progress
+		}
+		if len(e.Tags) > 0 {
+			msg += " - " + e.FormatTags()
+		}
+		msgs = append(msgs, msg)
+		for _, s := range e.Task.Subtasks {
+			msgs = append(msgs, fmt.Sprintf(subtaskLine, subtaskMark(s), s.Title))
+		}
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func subtaskMark(s events.Subtask) string {
+	if s.Done {
+		return markDone
+	}
+	return markTodo
+}
+
+func (c *Calendar) Overdue() []*events.Event {
+	now := validators.Now()
+	return c.lookup(func(idx EventIndex) ([]json.RawMessage, error) {
+		return idx.OpenTasks()
+	}, func(e *events.Event) bool {
+		return e.Overdue(now)
+	})
+}
+
+func (c *Calendar) ShowOverdue() string {
+	overdue := c.Overdue()
+	if len(overdue) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(overdueMessage, len(overdue))
+}
+
+func (c *Calendar) CarryOver() (string, error) {
+	now := validators.Now()
+	overdue := c.Overdue()
+	if len(overdue) == 0 {
+		return noCarryMessage, nil
+	}
+	rec := c.track()
+	lines := []string{fmt.Sprintf(carriedMessage, len(overdue))}
+	for _, e := range overdue {
+		c.touch(rec, e.ID)
+		due := e.StartAt.AddDate(0, 0, int(now.Sub(e.StartAt).Hours()/24))
+		for due.Before(now) {
+			due = due.AddDate(0, 0, 1)
+		}
+		if e.Reminder != nil {
+			e.Reminder.Stop()
+			e.Reminder.At = e.Reminder.At.Add(due.Sub(e.StartAt))
+			e.Reminder.Sent = false
+			e.Reminder.Rearm(c)
+		}
+		e.StartAt = due
+		lines = append(lines, fmt.Sprintf(carriedLine, e.Title, c.FormatDate(e.StartAt)))
+	}
+	c.commit(rec, opCarryOver, len(overdue))
+	return strings.Join(lines, "\n"), nil
+}
+
+func (c *Calendar) formatTask(e *events.Event) string {
+	state := markTodo + " не выполнена"
+	switch {
+	case e.Task.Done && e.Task.CompletedAt != nil:
+		state = markDone + " выполнена " + c.FormatDate(*e.Task.CompletedAt)
+	case e.Overdue(validators.Now()):
+		state = markOverdue + " просрочена"
+	}
+	if progress := e.Progress(); progress != "" {
+		state += ", подзадачи " + progress
+	}
+	return state
+}
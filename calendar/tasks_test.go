@@ -0,0 +1,73 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ilsft/Golendar/events"
+	validators "github.com/ilsft/Golendar/utils"
+)
+
+func TestTasksDoneOrderAndCarryOver(t *testing.T) {
+	c := NewCalendar(nil)
+	if _, err := c.AddEvent("Отчёт", "2030-05-01 10:00", events.PriorityHigh, events.Details{}, AddOptions{
+		Task:     true,
+		Subtasks: []string{"Собрать данные", "Написать выводы"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.AddEvent("Счёт", "2030-05-03 10:00", events.PriorityLow, events.Details{}, AddOptions{Task: true}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.AddEvent("Встреча", "2030-05-01 10:00", events.PriorityMedium, events.Details{}, AddOptions{}); err != nil {
+		t.Fatalf("Задача не должна пересекаться с событиями: %v", err)
+	}
+	tasks := c.Tasks(false)
+	if len(tasks) != 2 {
+		t.Fatalf("Ожидалось 2 задачи, получено %d", len(tasks))
+	}
+	report, bill := tasks[0], tasks[1]
+
+	if _, err := c.SetEventReminder(bill.ID, "Оплатить", "2030-05-02 10:00"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.SetEventReminder(report.ID, "Сдать отчёт", "2030-05-01 09:00"); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { c.CalendarEvents[report.ID].Reminder.Stop() }()
+	if _, err := c.CheckSubtask(report.ID, 1, true); err != nil || report.Progress() != "1/2" {
+		t.Fatalf("Ожидался прогресс 1/2, получено %q (%v)", report.Progress(), err)
+	}
+	if _, err := c.CompleteTask(bill.ID); err != nil {
+		t.Fatal(err)
+	}
+	if !bill.Task.Done || bill.Task.CompletedAt == nil || bill.ReminderDue() {
+		t.Error("Выполненная задача должна получить время завершения и остановить напоминание")
+	}
+	if _, err := c.CompleteTask(bill.ID); err == nil {
+		t.Error("Повторное выполнение должно вернуть ошибку")
+	}
+	if len(c.Tasks(false)) != 1 || len(c.Tasks(true)) != 2 {
+		t.Error("Выполненные задачи должны скрываться без --all")
+	}
+
+	defer func() { validators.Now = time.Now }()
+	now := time.Date(2030, 5, 2, 12, 0, 0, 0, time.Local)
+	validators.Now = func() time.Time { return now }
+	if list := c.Tasks(true); list[0].ID != report.ID || list[1].ID != bill.ID {
+		t.Error("Просроченные задачи должны идти первыми, выполненные — последними")
+	}
+	if _, err := c.CarryOver(); err != nil {
+		t.Fatal(err)
+	}
+	want := time.Date(2030, 5, 3, 10, 0, 0, 0, time.Local)
+	if !report.StartAt.Equal(want) || len(c.Overdue()) != 0 {
+		t.Errorf("Просроченная задача должна переноситься на %v, получено %v", want, report.StartAt)
+	}
+	if !report.Reminder.At.Equal(want.Add(-time.Hour)) {
+		t.Errorf("Напоминание должно сдвигаться вместе со сроком, получено %v", report.Reminder.At)
+	}
+	if _, err := c.Undo(); err != nil || !c.CalendarEvents[report.ID].StartAt.Before(now) {
+		t.Errorf("Перенос должен отменяться: %v", err)
+	}
+}
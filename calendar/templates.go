@@ -0,0 +1,223 @@
+package calendar
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ilsft/Golendar/events"
+)
+
+const (
+	templateSavedMessage   = "Шаблон %s сохранён"
+	templateUpdatedMessage = "Шаблон %s обновлён"
+	templateDeletedMessage = "Шаблон %s удалён"
+	templateLine           = "%s - %s - %s"
+	templateParamsLine     = "  параметры: %s"
+	templateReminderLine   = "  напоминание за %s: %s"
+)
+
+var (
+	errorNoTemplates      = "шаблонов нет"
+	errorTemplateNotFound = "шаблон %s не найден"
+	errorTemplateName     = errors.New("имя шаблона не указано")
+	errorTemplateDuration = "неверная длительность шаблона %q, ожидается например 30m или 1h"
+	errorTemplateReminder = "неверное время напоминания шаблона %q, ожидается например 15m"
+	errorMissingParams    = "не заданы параметры шаблона %s: %s"
+	errorUnknownParams    = "шаблон %s не использует параметры: %s"
+)
+
+var placeholder = regexp.MustCompile(`\{([\p{L}\p{N}_-]+)\}`)
+
+type Template struct {
+	Name        string            `json:"name"`
+	Title       string            `json:"title"`
+	Priority    events.Priority   `json:"priority"`
+	Duration    string            `json:"duration,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Location    string            `json:"location,omitempty"`
+	URL         string            `json:"url,omitempty"`
+	Reminder    *TemplateReminder `json:"reminder,omitempty"`
+}
+
+type TemplateReminder struct {
+	Before  string `json:"before"`
+	Message string `json:"message"`
+}
+
+func (t *Template) fields() []*string {
+	fields := []*string{&t.Title, &t.Description, &t.Location, &t.URL}
+	if t.Reminder != nil {
+		fields = append(fields, &t.Reminder.Message)
+	}
+	return fields
+}
+
+func (t *Template) Params() []string {
+	var params []string
+	for _, field := range t.fields() {
+		for _, m := range placeholder.FindAllStringSubmatch(*field, -1) {
+			if !slices.Contains(params, m[1]) {
+				params = append(params, m[1])
+			}
+		}
+	}
+	return params
+}
+
+func (t *Template) validate() error {
+	t.Name = strings.TrimSpace(t.Name)
+	if t.Name == "" {
+		return errorTemplateName
+	}
+	priority, err := events.ParsePriority(string(t.Priority))
+	if err != nil {
+		return err
+	}
+	t.Priority = priority
+	if t.Duration != "" {
+		d, err := time.ParseDuration(t.Duration)
+		if err != nil || d <= 0 {
+			return fmt.Errorf(errorTemplateDuration, t.Duration)
+		}
+	}
+	t.Tags, err = events.NormalizeTags(t.Tags)
+	if err != nil {
+		return err
+	}
+	if t.Reminder != nil {
+		d, err := time.ParseDuration(t.Reminder.Before)
+		if err != nil || d < 0 {
+			return fmt.Errorf(errorTemplateReminder, t.Reminder.Before)
+		}
+	}
+	return nil
+}
+
+func (t Template) expand(values map[string]string) (Template, error) {
+	params := t.Params()
+	var missing, unknown []string
+	for _, p := range params {
+		if _, ok := values[p]; !ok {
+			missing = append(missing, p)
+		}
+	}
+	for key := range values {
+		if !slices.Contains(params, key) {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(missing) > 0 {
+		return t, fmt.Errorf(errorMissingParams, t.Name, strings.Join(missing, ", "))
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return t, fmt.Errorf(errorUnknownParams, t.Name, strings.Join(unknown, ", "))
+	}
+	if t.Reminder != nil {
+		r := *t.Reminder
+		t.Reminder = &r
+	}
+	for _, field := range t.fields() {
+		*field = placeholder.ReplaceAllStringFunc(*field, func(m string) string {
+			return values[m[1:len(m)-1]]
+		})
+	}
+	return t, nil
+}
+
+func (c *Calendar) SaveTemplate(t Template) (string, error) {
+	err := t.validate()
+	if err != nil {
+		return "", err
+	}
+	if c.Templates == nil {
+		c.Templates = make(map[string]*Template)
+	}
+	key := strings.ToLower(t.Name)
+	msg := templateSavedMessage
+	if _, ok := c.Templates[key]; ok {
+		msg = templateUpdatedMessage
+	}
+	c.Templates[key] = &t
+	return fmt.Sprintf(msg, t.Name), nil
+}
+
+func (c *Calendar) DeleteTemplate(name string) (string, error) {
+	key := strings.ToLower(strings.TrimSpace(name))
+	t, ok := c.Templates[key]
+	if !ok {
+		return "", fmt.Errorf(errorTemplateNotFound, name)
+	}
+	delete(c.Templates, key)
+	return fmt.Sprintf(templateDeletedMessage, t.Name), nil
+}
+
+func (c *Calendar) Template(name string) (*Template, error) {
+	t, ok := c.Templates[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return nil, fmt.Errorf(errorTemplateNotFound, name)
+	}
+	return t, nil
+}
+
+func (c *Calendar) ShowTemplates() (string, error) {
+	if len(c.Templates) == 0 {
+		return "", errors.New(errorNoTemplates)
+	}
+	keys := make([]string, 0, len(c.Templates))
+	for key := range c.Templates {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var msgs []string
+	for _, key := range keys {
+		t := c.Templates[key]
+		msg := fmt.Sprintf(templateLine, t.Name, t.Title, t.Priority.Colorize())
+		if t.Duration != "" {
+			msg += " - " + t.Duration
+		}
+		if len(t.Tags) > 0 {
+			msg += " - #" + strings.Join(t.Tags, " #")
+		}
+		msgs = append(msgs, msg)
+		if params := t.Params(); len(params) > 0 {
+			msgs = append(msgs, fmt.Sprintf(templateParamsLine, strings.Join(params, ", ")))
+		}
+		if t.Reminder != nil {
+			msgs = append(msgs, fmt.Sprintf(templateReminderLine, t.Reminder.Before, t.Reminder.Message))
+		}
+	}
+	return strings.Join(msgs, "\n"), nil
+}
+
+func (c *Calendar) AddFromTemplate(name string, dateStr string, values map[string]string, force bool) (string, error) {
+	t, err := c.Template(name)
+	if err != nil {
+		return "", err
+	}
+	event, err := t.expand(values)
+	if err != nil {
+		return "", err
+	}
+	opts := AddOptions{
+		End:   event.Duration,
+		Force: force,
+		Tags:  event.Tags,
+	}
+	if event.Reminder != nil {
+		before, _ := time.ParseDuration(event.Reminder.Before)
+		opts.Reminder = &ReminderSpec{Message: event.Reminder.Message, Before: before}
+	}
+	details := events.Details{
+		Description: event.Description,
+		Location:    event.Location,
+		URL:         event.URL,
+	}
+	return c.AddEvent(event.Title, dateStr, event.Priority, details, opts)
+}
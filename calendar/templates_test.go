@@ -0,0 +1,64 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ilsft/Golendar/events"
+)
+
+func TestTemplateInstantiation(t *testing.T) {
+	c := NewCalendar(nil)
+	msg, err := c.SaveTemplate(Template{
+		Name:     "1:1",
+		Title:    "1:1 с {name}",
+		Priority: "m",
+		Duration: "30m",
+		Tags:     []string{"Work", "1on1"},
+		Location: "{room}",
+		Reminder: &TemplateReminder{Before: "15m", Message: "Скоро 1:1 с {name}"},
+	})
+	if err != nil || !strings.Contains(msg, "сохранён") {
+		t.Fatalf("Шаблон должен сохраняться: %q %v", msg, err)
+	}
+	if _, err := c.SaveTemplate(Template{Name: "плохой", Title: "x", Priority: "high", Duration: "полчаса"}); err == nil {
+		t.Error("Неверная длительность шаблона должна отклоняться")
+	}
+
+	if _, err := c.AddFromTemplate("1:1", "2030-05-01 10:00", map[string]string{"name": "Иваном"}, false); err == nil ||
+		!strings.Contains(err.Error(), "room") {
+		t.Errorf("Незаданный параметр должен называться в ошибке, получено %v", err)
+	}
+	if _, err := c.AddFromTemplate("1:1", "2030-05-01 10:00", map[string]string{"name": "Иваном", "room": "3", "team": "x"}, false); err == nil {
+		t.Error("Лишний параметр должен отклоняться")
+	}
+	if _, err := c.AddFromTemplate("1:1", "2030-05-01 10:00", map[string]string{"name": "Иваном", "room": "Переговорная 3"}, false); err != nil {
+		t.Fatal(err)
+	}
+
+	var event *events.Event
+	for _, e := range c.CalendarEvents {
+		event = e
+	}
+	defer event.Reminder.Stop()
+	if event.Title != "1:1 с Иваном" || event.Location != "Переговорная 3" || event.Priority != events.PriorityMedium {
+		t.Errorf("Параметры шаблона не подставлены: %+v", event)
+	}
+	if event.End(time.Hour).Sub(event.StartAt) != 30*time.Minute || event.FormatTags() != "#1on1 #work" {
+		t.Errorf("Ожидались длительность 30m и теги шаблона, получено %s %s", event.End(time.Hour).Sub(event.StartAt), event.FormatTags())
+	}
+	if event.Reminder == nil || event.Reminder.Message != "Скоро 1:1 с Иваном" || !event.Reminder.At.Equal(event.StartAt.Add(-15*time.Minute)) {
+		t.Errorf("Ожидалось напоминание за 15 минут, получено %+v", event.Reminder)
+	}
+	msg, err = c.AddFromTemplate("1:1", "in 10 minutes", map[string]string{"name": "Олегом", "room": "5"}, true)
+	if err != nil || !strings.Contains(msg, "напоминание не добавлено") {
+		t.Errorf("Пропущенное напоминание должно сопровождаться предупреждением: %q %v", msg, err)
+	}
+	if c.Templates["1:1"].Title != "1:1 с {name}" {
+		t.Error("Подстановка не должна менять сам шаблон")
+	}
+	if _, err := c.DeleteTemplate("1:1"); err != nil || len(c.Templates) != 0 {
+		t.Errorf("Шаблон должен удаляться: %v", err)
+	}
+}
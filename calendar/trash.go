@@ -0,0 +1,118 @@
+package calendar
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ilsft/Golendar/events"
+	validators "github.com/ilsft/Golendar/utils"
+)
+
+const DefaultTrashRetention = 30 * 24 * time.Hour
+
+const (
+	trashMovedMessage    = "Событие: %s перемещено в корзину"
+	trashRestoredMessage = "Событие: %s восстановлено из корзины на %s"
+	trashEmptiedMessage  = "Корзина очищена, удалено событий: %d"
+	trashHeader          = "🗑️ Корзина"
+	trashLine            = "#%d %s - %s - %s - удалено %s"
+	opTrashRestore       = "восстановление события %s из корзины"
+	opTrashEmpty         = "очистка корзины: %d"
+)
+
+var (
+	errorTrashEmpty    = "корзина пуста"
+	errorNotFoundTrash = "в корзине нет события с ID %s"
+)
+
+type TrashItem struct {
+	Event     *events.Event `json:"event"`
+	DeletedAt time.Time     `json:"deleted_at"`
+}
+
+func (c *Calendar) DeleteEvent(id string) (string, error) {
+	event, err := c.GetEventByID(id)
+	if err != nil {
+		return "", err
+	}
+	rec := c.track(id)
+	event.Reminder.Stop()
+	delete(c.CalendarEvents, id)
+	c.Trash[id] = &TrashItem{Event: event, DeletedAt: validators.Now()}
+	c.commit(rec, opDelete, event.Title)
+	return fmt.Sprintf(trashMovedMessage, event.Title), nil
+}
+
+func (c *Calendar) TrashedEvents() []*TrashItem {
+	list := make([]*TrashItem, 0, len(c.Trash))
+	for _, item := range c.Trash {
+		list = append(list, item)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].DeletedAt.Equal(list[j].DeletedAt) {
+			return list[i].Event.ID < list[j].Event.ID
+		}
+		return list[i].DeletedAt.After(list[j].DeletedAt)
+	})
+	return list
+}
+
+func (c *Calendar) FormatTrash(list []*TrashItem) string {
+	if len(list) == 0 {
+		return errorTrashEmpty
+	}
+	msgs := []string{trashHeader}
+	for i, item := range list {
+		msgs = append(msgs, fmt.Sprintf(trashLine, i+1, item.Event.ID, item.Event.Title,
+			c.FormatDate(item.Event.StartAt), c.FormatDate(item.DeletedAt)))
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func (c *Calendar) RestoreFromTrash(id string) (string, error) {
+	item, ok := c.Trash[id]
+	if !ok {
+		return "", fmt.Errorf(errorNotFoundTrash, id)
+	}
+	rec := c.track(id)
+	delete(c.Trash, id)
+	c.CalendarEvents[id] = item.Event
+	if item.Event.ReminderDue() {
+		item.Event.Reminder.Rearm(c)
+	}
+	c.commit(rec, opTrashRestore, item.Event.Title)
+	return fmt.Sprintf(trashRestoredMessage, item.Event.Title, c.FormatDate(item.Event.StartAt)), nil
+}
+
+func (c *Calendar) EmptyTrash() (string, error) {
+	if len(c.Trash) == 0 {
+		return "", errors.New(errorTrashEmpty)
+	}
+	rec := c.track()
+	for id := range c.Trash {
+		c.touch(rec, id)
+	}
+	count := len(c.Trash)
+	c.Trash = make(map[string]*TrashItem)
+	c.commit(rec, opTrashEmpty, count)
+	return fmt.Sprintf(trashEmptiedMessage, count), nil
+}
+
+func (c *Calendar) PurgeTrash() int {
+	if c.TrashRetention <= 0 {
+		return 0
+	}
+	deadline := validators.Now().Add(-c.TrashRetention)
+	purged := 0
+	for id, item := range c.Trash {
+		if item.DeletedAt.Before(deadline) {
+			delete(c.Trash, id)
+			c.emit(EventPurged, id)
+			purged++
+		}
+	}
+	return purged
+}
@@ -0,0 +1,40 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ilsft/Golendar/events"
+	validators "github.com/ilsft/Golendar/utils"
+)
+
+func TestTrashRestoreEmptyAndPurge(t *testing.T) {
+	c := NewCalendar(nil)
+	c.CalendarEvents["a"] = &events.Event{ID: "a", Title: "Турнир"}
+	c.CalendarEvents["b"] = &events.Event{ID: "b", Title: "Отчёт"}
+
+	if _, err := c.DeleteEvent("a"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.CalendarEvents["a"]; ok || c.Trash["a"] == nil {
+		t.Fatal("Удалённое событие должно попасть в корзину")
+	}
+	if _, err := c.RestoreFromTrash("a"); err != nil || c.CalendarEvents["a"] == nil {
+		t.Fatalf("Событие должно восстанавливаться из корзины: %v", err)
+	}
+
+	c.DeleteEvent("a")
+	c.DeleteEvent("b")
+	if _, err := c.EmptyTrash(); err != nil || len(c.Trash) != 0 {
+		t.Fatalf("Корзина должна очищаться: %v", err)
+	}
+	if _, err := c.Undo(); err != nil || len(c.Trash) != 2 {
+		t.Fatalf("Отмена очистки должна вернуть события в корзину: %v", err)
+	}
+
+	defer func() { validators.Now = time.Now }()
+	validators.Now = func() time.Time { return time.Now().Add(31 * 24 * time.Hour) }
+	if purged := c.PurgeTrash(); purged != 2 || len(c.Trash) != 0 {
+		t.Errorf("Ожидалась очистка 2 старых событий, очищено %d", purged)
+	}
+}
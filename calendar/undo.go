@@ -0,0 +1,215 @@
+package calendar
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/ilsft/Golendar/events"
+	"github.com/ilsft/Golendar/logger"
+	validators "github.com/ilsft/Golendar/utils"
+)
+
+const maxUndo = 50
+
+const (
+	opAdd            = "добавление события %s"
+	opDelete         = "удаление события %s"
+	opEdit           = "изменение события %s"
+	opReminderSet    = "напоминание для события %s"
+	opReminderRemove = "удаление напоминания события %s"
+	opReminderStop   = "остановка напоминания события %s"
+	opTag            = "изменение тегов события %s"
+	opRenameTag      = "переименование тега %s"
+	opImport         = "импорт событий: %d"
+	opRestore        = "восстановление из снимка %s"
+)
+
+const (
+	undoneMessage = "Отменено: %s"
+	redoneMessage = "Повторено: %s"
+)
+
+var (
+	errNothingToUndo = errors.New("нечего отменять")
+	errNothingToRedo = errors.New("нечего повторять")
+)
+
+type Operation struct {
+	Description string    `json:"description"`
+	At          time.Time `json:"at"`
+	Changes     []Change  `json:"changes"`
+}
+
+type Change struct {
+	ID            string          `json:"id"`
+	Before        json.RawMessage `json:"before"`
+	After         json.RawMessage `json:"after"`
+	TrashedBefore *time.Time      `json:"trashed_before,omitempty"`
+	TrashedAfter  *time.Time      `json:"trashed_after,omitempty"`
+}
+
+type recorder struct {
+	changes []Change
+	seen    map[string]bool
+}
+
+func (c *Calendar) track(ids ...string) *recorder {
+	r := &recorder{seen: make(map[string]bool)}
+	for _, id := range ids {
+		c.touch(r, id)
+	}
+	return r
+}
+
+func (c *Calendar) touch(r *recorder, id string) {
+	if r.seen[id] {
+		return
+	}
+	r.seen[id] = true
+	before, trashed := c.snapshot(id)
+	r.changes = append(r.changes, Change{ID: id, Before: before, TrashedBefore: trashed})
+}
+
+func (c *Calendar) snapshot(id string) (json.RawMessage, *time.Time) {
+	c.fetch(id)
+	event, ok := c.CalendarEvents[id]
+	var trashed *time.Time
+	if item, inTrash := c.Trash[id]; !ok && inTrash {
+		event = item.Event
+		trashed = &item.DeletedAt
+	}
+	if event == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil, nil
+	}
+	return data, trashed
+}
+
+func (c *Calendar) commit(r *recorder, description string, args ...any) {
+	op := Operation{
+		Description: fmt.Sprintf(description, args...),
+		At:          validators.Now(),
+	}
+	for _, ch := range r.changes {
+		ch.After, ch.TrashedAfter = c.snapshot(ch.ID)
+		if bytes.Equal(ch.Before, ch.After) && sameTime(ch.TrashedBefore, ch.TrashedAfter) {
+			continue
+		}
+		op.Changes = append(op.Changes, ch)
+	}
+	if len(op.Changes) == 0 {
+		return
+	}
+	for _, ch := range op.Changes {
+		c.journal(opEvents[description], ch.ID, ch.After, ch.TrashedAfter)
+		logger.Debug(op.Description, "event_id", ch.ID)
+	}
+	c.UndoStack = append(c.UndoStack, op)
+	if len(c.UndoStack) > maxUndo {
+		c.UndoStack = slices.Clone(c.UndoStack[len(c.UndoStack)-maxUndo:])
+	}
+	c.RedoStack = nil
+}
+
+func (c *Calendar) Undo() (string, error) {
+	if len(c.UndoStack) == 0 {
+		return "", errNothingToUndo
+	}
+	op := c.UndoStack[len(c.UndoStack)-1]
+	for i := len(op.Changes) - 1; i >= 0; i-- {
+		ch := op.Changes[i]
+		err := c.applyState(ch.ID, ch.Before, ch.TrashedBefore, true)
+		if err != nil {
+			return "", err
+		}
+		c.journal(ChangeUndone, ch.ID, ch.Before, ch.TrashedBefore)
+	}
+	c.UndoStack = c.UndoStack[:len(c.UndoStack)-1]
+	c.RedoStack = append(c.RedoStack, op)
+	return fmt.Sprintf(undoneMessage, op.Description), nil
+}
+
+func (c *Calendar) Redo() (string, error) {
+	if len(c.RedoStack) == 0 {
+		return "", errNothingToRedo
+	}
+	op := c.RedoStack[len(c.RedoStack)-1]
+	for _, ch := range op.Changes {
+		err := c.applyState(ch.ID, ch.After, ch.TrashedAfter, true)
+		if err != nil {
+			return "", err
+		}
+		c.journal(ChangeRedone, ch.ID, ch.After, ch.TrashedAfter)
+	}
+	c.RedoStack = c.RedoStack[:len(c.RedoStack)-1]
+	c.UndoStack = append(c.UndoStack, op)
+	return fmt.Sprintf(redoneMessage, op.Description), nil
+}
+
+// forget drops undo/redo changes of events that left the calendar for good,
+// so undoing an older edit cannot bring an archived event back.
+func (c *Calendar) forget(list []*events.Event) {
+	ids := make(map[string]bool, len(list))
+	for _, event := range list {
+		ids[event.ID] = true
+	}
+	c.UndoStack = forgetChanges(c.UndoStack, ids)
+	c.RedoStack = forgetChanges(c.RedoStack, ids)
+}
+
+func forgetChanges(ops []Operation, ids map[string]bool) []Operation {
+	var kept []Operation
+	for _, op := range ops {
+		op.Changes = slices.DeleteFunc(slices.Clone(op.Changes), func(ch Change) bool {
+			return ids[ch.ID]
+		})
+		if len(op.Changes) > 0 {
+			kept = append(kept, op)
+		}
+	}
+	return kept
+}
+
+func (c *Calendar) applyState(id string, state json.RawMessage, trashed *time.Time, rearm bool) error {
+	var event *events.Event
+	if len(state) > 0 && string(state) != "null" {
+		event = &events.Event{}
+		err := json.Unmarshal(state, event)
+		if err != nil {
+			return fmt.Errorf(errorDeSerialJSON, err)
+		}
+		event.Restore()
+	}
+	c.fetch(id)
+	if current, ok := c.CalendarEvents[id]; ok {
+		current.Reminder.Stop()
+	}
+	delete(c.CalendarEvents, id)
+	delete(c.Trash, id)
+	if event == nil {
+		return nil
+	}
+	if trashed != nil {
+		c.Trash[id] = &TrashItem{Event: event, DeletedAt: *trashed}
+		return nil
+	}
+	if rearm && event.ReminderDue() {
+		event.Reminder.Rearm(c)
+	}
+	c.CalendarEvents[id] = event
+	return nil
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
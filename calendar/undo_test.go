@@ -0,0 +1,99 @@
+package calendar
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ilsft/Golendar/events"
+	"github.com/ilsft/Golendar/storage"
+)
+
+func TestUndoRedoPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "calendar.json")
+	c := NewCalendar(storage.NewJsonStorage(path))
+	if _, err := c.AddEvent("Турнир", "2030-05-01 10:00", events.PriorityHigh, events.Details{}, AddOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	var id string
+	for id = range c.CalendarEvents {
+	}
+	if _, err := c.SetEventReminder(id, "Взять форму", "2030-05-01 09:00"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.DeleteEvent(id); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	restored := NewCalendar(storage.NewJsonStorage(path))
+	if err := restored.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := restored.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	event, err := restored.GetEventByID(id)
+	if err != nil {
+		t.Fatalf("Отмена удаления должна вернуть событие: %v", err)
+	}
+	if event.Reminder == nil || event.Reminder.Message != "Взять форму" {
+		t.Errorf("Отмена должна вернуть напоминание, получено %+v", event.Reminder)
+	}
+	event.Reminder.Stop()
+
+	if _, err := restored.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if restored.CalendarEvents[id].Reminder != nil {
+		t.Error("Вторая отмена должна убрать напоминание")
+	}
+	if _, err := restored.Redo(); err != nil {
+		t.Fatal(err)
+	}
+	if restored.CalendarEvents[id].Reminder == nil {
+		t.Error("Повтор должен вернуть напоминание")
+	}
+	restored.CalendarEvents[id].Reminder.Stop()
+
+	if _, err := restored.EditEvent(id, events.Changes{}, false); err != nil {
+		t.Fatal(err)
+	}
+	if len(restored.RedoStack) != 1 {
+		t.Errorf("Изменение без разницы не должно сбрасывать повтор, получено %d", len(restored.RedoStack))
+	}
+}
+
+func TestUndoKeepsStoppedReminder(t *testing.T) {
+	c := NewCalendar(nil)
+	if _, err := c.AddEvent("Турнир", "2030-05-01 10:00", events.PriorityHigh, events.Details{}, AddOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	var event *events.Event
+	for _, event = range c.CalendarEvents {
+	}
+	if _, err := event.AddReminder("Взять форму", time.Now().Add(100*time.Millisecond), c); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.CancelEventReminder(event.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.TagEvent(event.ID, []string{"спорт"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Undo(); err != nil {
+		t.Fatal(err)
+	}
+
+	restored := c.CalendarEvents[event.ID]
+	if restored.Reminder == nil || !restored.Reminder.Stopped {
+		t.Fatalf("После отмены тега напоминание должно остаться остановленным, получено %+v", restored.Reminder)
+	}
+	select {
+	case msg := <-c.Notification:
+		t.Errorf("Остановленное напоминание сработало после отмены: %s", msg)
+	case <-time.After(300 * time.Millisecond):
+	}
+}
@@ -13,28 +13,58 @@ import (
 )
 
 type Cmd struct {
-	calendar *calendar.Calendar
-	logger   *HistoryLogger
-	reader   *bufio.Reader
+	calendar  *calendar.Calendar
+	logger    *HistoryLogger
+	reader    *bufio.Reader
+	lastList  []string
+	lastFree  []calendar.Slot
+	lastTrash []string
+	logAttrs  []any
 }
 
-func NewCmd(c *calendar.Calendar, logger *HistoryLogger) *Cmd {
+func NewCmd(c *calendar.Calendar, history *HistoryLogger) *Cmd {
 	return &Cmd{
 		calendar: c,
-		logger:   logger,
+		logger:   history,
 		reader:   bufio.NewReader(os.Stdin),
 	}
 }
 
 func (c *Cmd) completer(d prompt.Document) []prompt.Suggest {
+	if prefix, ok := c.tagPrefix(d); ok {
+		return c.tagSuggestions(prefix)
+	}
 	suggestions := []prompt.Suggest{
 		{Text: "add", Description: "Добавить событие"},
 		{Text: "list", Description: "Показать все события"},
+		{Text: "show", Description: "Показать подробности события"},
+		{Text: "search", Description: "Полнотекстовый поиск по событиям"},
+		{Text: "conflicts", Description: "Пересечения событий"},
+		{Text: "free", Description: "Найти свободное время"},
+		{Text: "book", Description: "Создать событие в свободном окне"},
 		{Text: "remove", Description: "Удалить событие"},
 		{Text: "update", Description: "Изменить событие"},
 		{Text: "add_rm", Description: "Добавить напоминание"},
 		{Text: "stop_rm", Description: "Остановить напоминание"},
 		{Text: "remove_rm", Description: "Удалить напоминание"},
+		{Text: "tag", Description: "Добавить теги событию"},
+		{Text: "untag", Description: "Удалить теги события"},
+		{Text: "tags", Description: "Список тегов, tags rename"},
+		{Text: "attach", Description: "Вложения события: add, list, get"},
+		{Text: "trash", Description: "Корзина: list, restore, empty"},
+		{Text: "archive", Description: "Перенести старые события в архив"},
+		{Text: "undo", Description: "Отменить последнее изменение"},
+		{Text: "redo", Description: "Повторить отменённое изменение"},
+		{Text: "template", Description: "Шаблоны событий: save, list, delete"},
+		{Text: "todo", Description: "Задачи: добавить, список, подзадачи, перенос"},
+		{Text: "done", Description: "Отметить задачу выполненной"},
+		{Text: "undone", Description: "Вернуть задачу в работу"},
+		{Text: "journal", Description: "Журнал изменений событий"},
+		{Text: "replay", Description: "Состояние календаря на момент из журнала"},
+		{Text: "import", Description: "Импортировать события из файла календаря"},
+		{Text: "backup", Description: "Снимки календаря: list, diff, restore"},
+		{Text: "pack", Description: "Упаковать каталог в zip-архив"},
+		{Text: "unpack", Description: "Распаковать zip-архив в каталог"},
 		{Text: "history", Description: "Показать историю ввода/вывода"},
 		{Text: "help", Description: "Показать справку"},
 		{Text: "exit", Description: "Выйти из программы"},
@@ -42,21 +72,64 @@ func (c *Cmd) completer(d prompt.Document) []prompt.Suggest {
 	return prompt.FilterHasPrefix(suggestions, d.GetWordBeforeCursor(), true)
 }
 
+func (c *Cmd) tagPrefix(d prompt.Document) (string, bool) {
+	words := strings.Fields(d.TextBeforeCursor())
+	current := d.GetWordBeforeCursor()
+	if current != "" && len(words) > 0 {
+		words = words[:len(words)-1]
+	}
+	if len(words) == 0 {
+		return "", false
+	}
+	last := words[len(words)-1]
+	cmd := strings.ToLower(words[0])
+	switch {
+	case last == "--"+optTag || last == "--"+optNotTag:
+		return current, true
+	case (cmd == "tag" || cmd == "untag") && len(words) >= 2:
+		return current, true
+	case cmd == "tags" && len(words) == 2 && strings.ToLower(words[1]) == "rename":
+		return current, true
+	}
+	return "", false
+}
+
+func (c *Cmd) tagSuggestions(word string) []prompt.Suggest {
+	head := ""
+	prefix := strings.TrimPrefix(word, "#")
+	if i := strings.LastIndex(word, ","); i >= 0 {
+		head = word[:i+1]
+		prefix = word[i+1:]
+	}
+	counts := c.calendar.TagCounts()
+	var suggestions []prompt.Suggest
+	for _, tag := range c.calendar.Tags() {
+		if strings.HasPrefix(tag, strings.ToLower(prefix)) {
+			suggestions = append(suggestions, prompt.Suggest{
+				Text:        head + tag,
+				Description: fmt.Sprintf(tagSuggestDescription, counts[tag]),
+			})
+		}
+	}
+	return suggestions
+}
+
 func (c *Cmd) executor(input string) {
+	c.logAttrs = nil
 	parts, err := shlex.Split(input)
 	if err != nil {
-		c.handlePrint(err.Error())
-		logger.LogError(err.Error())
+		c.handleError(err.Error())
 		return
 	}
 
-	c.logger.logMessage(input)
+	c.logger.logMessage(kindInput, input)
 	if len(parts) == 0 {
-		c.handlePrint(fmt.Sprint(emptyInput, "\n", deafaultMessage))
-		logger.LogError(fmt.Sprint(emptyInput, "\n", deafaultMessage))
+		c.handleError(fmt.Sprint(emptyInput, "\n", deafaultMessage))
 		return
 	}
 	cmd := strings.ToLower(parts[0])
+	c.logAttrs = []any{"command", cmd}
+	logger.Debug(input, c.logAttrs...)
 	switch cmd {
 	case "add":
 		c.handleAddCmd(parts)
@@ -70,10 +143,56 @@ func (c *Cmd) executor(input string) {
 		c.handleStopReminderCmd(parts)
 	case "remove_rm":
 		c.handleDeleteReminderCmd(parts)
+	case "search":
+		c.handleSearchCmd(parts)
+	case "show":
+		c.handleShowEventCmd(parts)
+	case "conflicts":
+		c.handleConflictsCmd(parts)
+	case "free":
+		c.handleFreeCmd(parts)
+	case "book":
+		c.handleBookCmd(parts)
+	case "tag":
+		c.handleTagCmd(parts, true)
+	case "untag":
+		c.handleTagCmd(parts, false)
+	case "tags":
+		c.handleTagsCmd(parts)
+	case "attach":
+		c.handleAttachCmd(parts)
 	case "list":
-		c.handleShowEventsCmd()
+		c.handleShowEventsCmd(parts)
+	case "template":
+		c.handleTemplateCmd(parts)
+	case "todo":
+		c.handleTodoCmd(parts)
+	case "done":
+		c.handleDoneCmd(parts, true)
+	case "undone":
+		c.handleDoneCmd(parts, false)
+	case "trash":
+		c.handleTrashCmd(parts)
+	case "archive":
+		c.handleArchiveCmd(parts)
+	case "undo":
+		c.handleUndoCmd()
+	case "redo":
+		c.handleRedoCmd()
+	case "import":
+		c.handleImportCmd(parts)
+	case "backup":
+		c.handleBackupCmd(parts)
+	case "pack":
+		c.handlePackCmd(parts)
+	case "unpack":
+		c.handleUnpackCmd(parts)
 	case "history":
-		c.handleShowLogsCmd()
+		c.handleShowLogsCmd(parts)
+	case "journal":
+		c.handleJournalCmd(parts)
+	case "replay":
+		c.handleReplayCmd(parts)
 	case "help":
 		c.handleShowHelpCmd()
 	case "exit":
@@ -84,30 +203,28 @@ func (c *Cmd) executor(input string) {
 
 	err = c.calendar.Save()
 	if err != nil {
-		c.handlePrint(err.Error())
-		logger.LogError(err.Error())
+		c.handleError(err.Error())
 		return
 	}
 	err = c.logger.saveLogs()
 	if err != nil {
-		c.handlePrint(err.Error())
-		logger.LogError(err.Error())
+		c.handleError(err.Error())
 		return
 	}
 
 }
 
 func (c *Cmd) Run() {
+	err := c.logger.loadLogs()
+	if err != nil {
+		c.handleError(err.Error())
+	}
 	p := prompt.New(
 		c.executor,
 		c.completer,
 		prompt.OptionPrefix("> "),
+		prompt.OptionHistory(c.logger.inputs()),
 	)
-	err := c.logger.loadLogs()
-	if err != nil {
-		c.handlePrint(err.Error())
-		logger.LogError(err.Error())
-	}
 	go func() {
 		for msg := range c.calendar.Notification {
 			c.handlePrint(msg)
@@ -4,9 +4,14 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/ilsft/Golendar/calendar"
 	"github.com/ilsft/Golendar/events"
 	"github.com/ilsft/Golendar/logger"
+	"github.com/ilsft/Golendar/storage"
 	validators "github.com/ilsft/Golendar/utils"
 )
 
@@ -14,7 +19,9 @@ const (
 	unknownCommand     = "Неизвестная команда:"
 	deafaultMessage    = "Введите 'help' для списка команд"
 	emptyInput         = "Пустой ввод, повторите попытку"
-	inputNumberMessage = "Введите номер события: "
+	inputNumberMessage = "Несколько совпадений, введите номер события: "
+	confirmRestore     = "Восстановить календарь из снимка? (да/нет): "
+	restoreCancelled   = "Восстановление отменено"
 )
 
 var (
@@ -24,12 +31,97 @@ var (
 
 const patternTime = "2006-01-02 15:04:05"
 
+const (
+	optDescription = "desc"
+	optLocation    = "loc"
+	optURL         = "url"
+	optZone        = "tz"
+	optTag         = "tag"
+	optNotTag      = "not-tag"
+	optMode        = "mode"
+	optTitle       = "title"
+	optAt          = "at"
+	optPriority    = "priority"
+	optSort        = "sort"
+	optEnd         = "end"
+	optForce       = "force"
+	optArchived    = "archived"
+	optUntil       = "until"
+	optSeq         = "seq"
+	optSave        = "save"
+	optSince       = "since"
+	optGrep        = "grep"
+	optLast        = "last"
+	optErrorsOnly  = "errors-only"
+	optSub         = "sub"
+	optAll         = "all"
+	optFrom        = "from"
+	optTo          = "to"
+	optRemind      = "remind"
+	optRemindMsg   = "remind-msg"
+)
+
+var (
+	detailOptions = []string{optDescription, optLocation, optURL}
+	addOptions    = []string{optEnd, optDescription, optLocation, optURL, optFrom}
+	updateOptions = []string{optTitle, optAt, optEnd, optPriority, optDescription, optLocation, optURL}
+	forceOptions  = []string{optForce}
+	listFlags     = []string{optArchived}
+	listOptions   = []string{optZone, optTag, optNotTag, optSort, optFrom, optTo, optPriority}
+	searchOptions = []string{optMode}
+	replayOptions = []string{optUntil, optSeq, optSave}
+	historyOpts   = []string{optSince, optGrep, optLast}
+	historyFlags  = []string{optErrorsOnly}
+	todoOptions   = []string{optSub, optDescription, optLocation, optURL}
+	todoFlags     = []string{optAll}
+	templateOpts  = []string{optEnd, optTag, optRemind, optRemindMsg, optDescription, optLocation, optURL}
+)
+
 const eventShowMessage = "📅Cписок событий✅"
 
+const freeRange = 7 * 24 * time.Hour
+
+var errSlotNumber = "нет свободного окна с номером %s, сначала выполните free"
+
+const tagSuggestDescription = "событий: %d"
+
+const todoShowMessage = "📝Список задач"
+
 const (
-	errAddFormat      = `add "имя события" "дата и время" "приоритет"`
-	errUpdateFormat   = `введите: "новое имя события" "новая дата и время" "новый приоритет"`
-	errReminderFormat = `введите: "имя напоминания" "дата и время"`
+	packedMessage   = "Каталог %s упакован в %s"
+	unpackedMessage = "Архив %s распакован в %s"
+	replaySaved     = "Состояние #%d сохранено в %s"
+)
+
+const (
+	errAddFormat       = `add "имя события" "дата и время" "приоритет" [--end "окончание или 90m"] [--desc "описание"] [--loc "место"] [--url "ссылка"] [--force]`
+	dateFormatHint     = `дата: "2025-08-25 15:00", "tomorrow 9am", "next friday 15:00", "in 2 hours", "через 30 минут", "завтра в 10", "послезавтра"; пояс в конце: "15:00 Europe/Berlin"`
+	errUpdateFormat    = `[--title "имя"] [--at "дата и время"] [--end "окончание"] [--priority "приоритет"] [--desc ...] [--loc ...] [--url ...] [--force]`
+	errConflictsFormat = `conflicts ["с" "по"]`
+	errFreeFormat      = `free "длительность, например 1h или 30m" ["с" "по"]`
+	errBookFormat      = `book N "имя события" "приоритет" [--desc ...] [--loc ...] [--url ...] [--force]`
+	errReminderFormat  = `"имя напоминания" "дата и время"`
+	priorityHint       = `приоритет: high, medium, low (h/m/l, высокий/средний/низкий) или уровень из config.json`
+	errListFormat      = `list [--tz "Asia/Tokyo"] [--tag "work,client:acme"] [--not-tag "personal"] [--sort date|priority] [--from "дата"] [--to "дата"] [--priority high] [--archived]`
+	errSearchFormat    = `search [--mode substring|word|fuzzy] "запрос"`
+	errTagFormat       = `tag "имя события" "тег" ["тег" ...]`
+	errUntagFormat     = `untag "имя события" "тег" ["тег" ...]`
+	errTagsFormat      = `tags | tags rename "старый тег" "новый тег"`
+	errPackFormat      = `pack "каталог" "архив.zip"`
+	errUnpackFormat    = `unpack "архив.zip" "каталог"`
+	errAttachFormat    = `attach add "ссылка" "файл" | attach list "ссылка" | attach get "ссылка" "имя" ["каталог"]`
+	errImportFormat    = `import "файл или URI хранилища"`
+	errBackupFormat    = `backup list | backup diff "id" | backup restore "id"`
+	errTrashFormat     = `trash list | trash restore "ссылка" | trash empty`
+	errArchiveFormat   = `archive [дней]`
+	errJournalFormat   = `journal [N]`
+	errTodoFormat      = `todo add "имя задачи" "срок" "приоритет" [--sub "подзадача" ...] | todo list [--all] | todo check "ссылка" N | todo uncheck "ссылка" N | todo carry`
+	errDoneFormat      = `done "ссылка" | undone "ссылка"`
+	errTemplateFormat  = `template save "имя" "название с {параметрами}" "приоритет" [--end "1h"] [--tag "a,b"] [--remind "15m"] [--remind-msg "текст"] [--desc ...] [--loc ...] [--url ...] | template list | template delete "имя"`
+	errFromFormat      = `add --from "шаблон" "дата и время" [параметр=значение ...] [--force]`
+	errTemplateParam   = "неверный параметр шаблона %q, ожидается имя=значение"
+	errHistoryFormat   = `history [--since "дата и время"] [--grep "текст"] [--last N] [--errors-only]`
+	errReplayFormat    = `replay [--until "дата и время"] [--seq N] [--save "URI хранилища"]`
 )
 
 const helpMessage = `
@@ -40,80 +132,151 @@ const helpMessage = `
 ───────────[ Создание и просмотр событий ]───────────
   add      ✅    ┆ создать событие
                  ┆ формат: ` + errAddFormat + `
+                 ┆ ` + dateFormatHint + `
+                 ┆ ` + priorityHint + `
+                 ┆ без --end длительность берётся из config.json (schedule.default_duration)
+                 ┆ пересечение с другими событиями сохраняется только с --force
+                 ┆ из шаблона: ` + errFromFormat + `
+  template 🧩    ┆ шаблоны событий с параметрами {имя}, длительностью, тегами и напоминанием
+                 ┆ формат: ` + errTemplateFormat + `
+  show     🔎    ┆ подробности события (описание, место, ссылка)
+  search   🔍    ┆ поиск по названиям, описаниям, местам и напоминаниям
+                 ┆ формат: ` + errSearchFormat + `
   list     📒    ┆ список всех событий 
                  ┆ (id - имя события - дата и время - приоритет)
+                 ┆ формат: ` + errListFormat + `
+                 ┆ --tz добавляет время во втором часовом поясе
+                 ┆ --tag a,b → есть a или b; несколько --tag → все условия;
+                 ┆ --not-tag → исключить события с тегом
+                 ┆ --sort priority → сначала важные события
+                 ┆ --from/--to → события, начинающиеся в этом промежутке
+                 ┆ --priority → только события с этим приоритетом
+                 ┆ --archived → вместе с событиями из архива (📦)
+  conflicts ⚠️   ┆ пересечения событий (по умолчанию — начиная с текущего момента)
+                 ┆ формат: ` + errConflictsFormat + `
+                 ┆ ‼ — пересечение с событием высокого приоритета
+  free     🕒    ┆ свободное время в рабочие часы (по умолчанию на 7 дней вперёд)
+                 ┆ формат: ` + errFreeFormat + `
+  book     📌    ┆ создать событие в окне N из последнего вывода free
+                 ┆ формат: ` + errBookFormat + `
+
+──────────────[ Задачи ]───────────────
+  todo      📝   ┆ задачи со сроком: добавить, список (просроченные первыми), подзадачи, перенос
+                ┆ формат: ` + errTodoFormat + `
+                ┆ todo carry → перенести невыполненные просроченные задачи на ближайший день
+  done      ✅   ┆ отметить задачу выполненной, напоминание останавливается
+  undone    🔄   ┆ вернуть задачу в работу
+                ┆ формат: ` + errDoneFormat + `
 
 ────────────[ Работа с существующими событиями ]──────
-  remove    ❌  ┆ удалить событие
+  remove    ❌  ┆ переместить событие в корзину (напоминание останавливается)
+  trash     🗑️   ┆ корзина: просмотр, восстановление, очистка
+                ┆ формат: ` + errTrashFormat + `
+                ┆ события удаляются из корзины автоматически через trash.retention
   update    ✏️   ┆ изменить данные
-                ┆ формат: ` + errUpdateFormat + `
+                ┆ формат: update "ссылка" ` + errUpdateFormat + `
+                ┆ меняются только указанные поля, ответ — что было и что стало
   add_rm    🔔  ┆ добавить напоминание
-                ┆ формат: ` + errReminderFormat + `
+                ┆ формат: add_rm "ссылка" ` + errReminderFormat + `
   stop_rm   ⏸️   ┆ остановить напоминание
   remove_rm 🗑️   ┆ удалить напоминание
+  tag       🏷️   ┆ добавить теги
+                ┆ формат: ` + errTagFormat + `
+  untag     ✂️   ┆ удалить теги
+                ┆ формат: ` + errUntagFormat + `
+  tags      🔖  ┆ список тегов и переименование во всех событиях
+                ┆ формат: ` + errTagsFormat + `
+  attach    📎  ┆ файлы события в каталоге attachments/ zip-хранилища
+                ┆ формат: ` + errAttachFormat + `
 		
 ──────────────[ Сервисные команды ]───────────────
+  undo      ↩️   ┆ отменить последнее изменение (до 50 шагов, сохраняется между запусками)
+  redo      ↪️   ┆ повторить отменённое изменение
+  import    📥   ┆ импортировать события другого календаря
+                ┆ формат: ` + errImportFormat + `
+  archive   📦   ┆ перенести события старше N дней в архивы по годам
+                ┆ формат: ` + errArchiveFormat + `
+  backup    💾   ┆ снимки календаря
+                ┆ формат: ` + errBackupFormat + `
+  pack      📦   ┆ упаковать каталог в zip-архив
+                ┆ формат: ` + errPackFormat + `
+  unpack    📂   ┆ распаковать zip-архив в каталог
+                ┆ формат: ` + errUnpackFormat + `
   history   📜   ┆ показать журнал действий
+                ┆ формат: ` + errHistoryFormat + `
+                ┆ хранится не больше history.max_entries записей не старше history.max_age
+  journal   🧾   ┆ журнал изменений событий: кто, когда и что изменил
+                ┆ формат: ` + errJournalFormat + `
+  replay    ⏪   ┆ восстановить состояние календаря на момент из журнала
+                ┆ формат: ` + errReplayFormat + `
+                ┆ --save → записать восстановленное состояние в хранилище
   exit      🏁   ┆ выход из программы
 
 
-═══════════[ Как работают команды для событий ]═══════════
-1. Введите часть имени события:
-       пример → "meet"
-2. Программа покажет список совпадений:
-
-   ┌───┬───────────────────────────────┬───────────────────┐
-   │ № │ Имя события                   │ Дата и время      │
-   ├───┼───────────────────────────────┼───────────────────┤
-   │ 1 │ Meeting с клиентом            │ 25.08.2025 14:00  │
-   │ 2 │ Meeting с командой            │ 26.08.2025 10:00  │
-   └───┴───────────────────────────────┴───────────────────┘
+═══════════[ Как указать событие ]═══════════
+Команды remove, update, add_rm, stop_rm, remove_rm, show, tag, untag
+и attach принимают ссылку на событие:
+  • ID или его начало       → remove 1eeddedb
+  • #N — строка из list     → remove #3
+  • запрос по названию      → remove "турнир"
 
-3. Введите номер события (например: "2").
-4. Команда применится к выбранному событию.
+Если ссылке соответствует одно событие, команда применяется сразу.
+Если совпадений несколько, программа покажет список и попросит номер.
 
-─── Различие по вводимым данным:
-  • remove / stop_rm / remove_rm → только номер
-  • update → номер + новые данные ` + errUpdateFormat + `
+─── Данные можно передать в той же строке:
+  • update "ссылка" ` + errUpdateFormat + `
+  • add_rm "ссылка" ` + errReminderFormat + `
+Если данные не указаны, программа запросит их отдельно.
 `
 
 func (c *Cmd) notifyResult(msg string, err error) bool {
 	if err != nil {
-		c.handlePrint(err.Error())
-		logger.LogError(err.Error())
+		c.handleError(err.Error())
 		return false
 	}
 	c.handlePrint(msg)
-	logger.LogInfo(msg)
+	logger.Info(msg, c.logAttrs...)
 	return true
 }
 
 func (c *Cmd) notifyError(err error) bool {
 	if err != nil {
-		c.handlePrint(err.Error())
-		logger.LogError(err.Error())
+		c.handleError(err.Error())
 		return false
 	}
 	return true
 }
 
 func (c *Cmd) handleAddCmd(parts []string) {
+	parts, opts, err := parseOptions(parts, addOptions, forceOptions)
+	if !c.notifyError(err) {
+		return
+	}
+	if opts.has(optFrom) {
+		c.handleAddFromTemplate(parts, opts)
+		return
+	}
 	if len(parts) < 4 {
-		c.handlePrint(errAddFormat)
-		logger.LogError(errAddFormat)
+		c.handleError(errAddFormat)
 		return
 	}
 	title := parts[1]
 	date := parts[2]
 	priority := events.Priority(parts[3])
-	msg, err := c.calendar.AddEvent(title, date, priority)
+	details := events.Details{
+		Description: opts.value(optDescription),
+		Location:    opts.value(optLocation),
+		URL:         opts.value(optURL),
+	}
+	msg, err := c.calendar.AddEvent(title, date, priority, details, calendar.AddOptions{
+		End:   opts.value(optEnd),
+		Force: opts.has(optForce),
+	})
 	switch {
 	case errors.Is(err, validators.ErrEmptyTitle):
-		c.handlePrint(errEmptyTitle)
-		logger.LogError(errEmptyTitle)
+		c.handleError(errEmptyTitle)
 	case errors.Is(err, validators.ErrDateAlreadyPassed):
-		c.handlePrint(errPastTimeTravel)
-		logger.LogError(errPastTimeTravel)
+		c.handleError(errPastTimeTravel)
 	default:
 		if !c.notifyResult(msg, err) {
 			return
@@ -133,40 +296,279 @@ func (c *Cmd) handleDeleteCmd(title []string) {
 }
 
 func (c *Cmd) handleEditeCmd(parts []string) {
+	parts, opts, err := parseOptions(parts, updateOptions, forceOptions)
+	if !c.notifyError(err) {
+		return
+	}
 	event, err := c.selectEvents(parts)
 	if !c.notifyError(err) {
 		return
 	}
-	parts, err = c.readAndParseInput(errUpdateFormat)
+	parts = parts[2:]
+	if len(parts) == 0 && len(opts) == 0 {
+		parts, err = c.readAndParseInput(errUpdateFormat)
+		if !c.notifyError(err) {
+			return
+		}
+		parts, opts, err = parseOptions(parts, updateOptions, forceOptions)
+		if !c.notifyError(err) {
+			return
+		}
+	}
+	changes, ok := updateChanges(event, parts, opts)
+	if !ok {
+		c.handleError(errUpdateFormat)
+		return
+	}
+	msg, err := c.calendar.EditEvent(event.ID, changes, opts.has(optForce))
+	if !c.notifyResult(msg, err) {
+		return
+	}
+}
+
+func updateChanges(event *events.Event, args []string, opts options) (events.Changes, bool) {
+	var changes events.Changes
+	switch len(args) {
+	case 0:
+	case 3:
+		priority := events.Priority(args[2])
+		changes.Title, changes.Date, changes.Priority = &args[0], &args[1], &priority
+	default:
+		return changes, false
+	}
+	if opts.has(optTitle) {
+		title := opts.value(optTitle)
+		changes.Title = &title
+	}
+	if opts.has(optAt) {
+		date := opts.value(optAt)
+		changes.Date = &date
+	}
+	if opts.has(optEnd) {
+		end := opts.value(optEnd)
+		changes.End = &end
+	}
+	if opts.has(optPriority) {
+		priority := events.Priority(opts.value(optPriority))
+		changes.Priority = &priority
+	}
+	if opts.has(optDescription) || opts.has(optLocation) || opts.has(optURL) {
+		d := event.Details()
+		applyDetailOptions(&d, opts)
+		changes.Details = &d
+	}
+	return changes, !changes.IsEmpty()
+}
+
+func applyDetailOptions(d *events.Details, opts options) {
+	if opts.has(optDescription) {
+		d.Description = opts.value(optDescription)
+	}
+	if opts.has(optLocation) {
+		d.Location = opts.value(optLocation)
+	}
+	if opts.has(optURL) {
+		d.URL = opts.value(optURL)
+	}
+}
+
+func (c *Cmd) handleSearchCmd(parts []string) {
+	args, opts, err := parseOptions(parts, searchOptions, nil)
 	if !c.notifyError(err) {
 		return
 	}
-	if len(parts) < 3 {
-		c.handlePrint(errUpdateFormat)
-		logger.LogError(errUpdateFormat)
+	if len(args) < 2 {
+		c.handleError(errSearchFormat)
+		return
+	}
+	mode, err := calendar.ParseSearchMode(opts.value(optMode))
+	if !c.notifyError(err) {
+		return
+	}
+	c.notifyResult(c.calendar.ShowSearch(strings.Join(args[1:], " "), mode))
+}
+
+func (c *Cmd) handleFreeCmd(parts []string) {
+	if len(parts) != 2 && len(parts) != 4 {
+		c.handleError(errFreeFormat)
 		return
 	}
-	newTitle := parts[0]
-	newDate := parts[1]
-	newPriority := events.Priority(parts[2])
-	msg, err := c.calendar.EditEvent(event.ID, newTitle, newDate, newPriority)
+	duration, err := time.ParseDuration(parts[1])
+	if err != nil || duration <= 0 {
+		c.handleError(errFreeFormat)
+		return
+	}
+	from := validators.Now()
+	to := from.Add(freeRange)
+	if len(parts) == 4 {
+		from, err = validators.ValidateDate(parts[2])
+		if !c.notifyError(err) {
+			return
+		}
+		to, err = validators.ValidateDate(parts[3])
+		if !c.notifyError(err) {
+			return
+		}
+	}
+	c.lastFree = c.calendar.FreeSlots(duration, from, to)
+	c.handlePrint(c.calendar.FormatSlots(c.lastFree, duration))
+}
+
+func (c *Cmd) handleBookCmd(parts []string) {
+	parts, opts, err := parseOptions(parts, detailOptions, forceOptions)
+	if !c.notifyError(err) {
+		return
+	}
+	if len(parts) < 4 {
+		c.handleError(errBookFormat)
+		return
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(parts[1], "#"))
+	if err != nil || n < 1 || n > len(c.lastFree) {
+		c.notifyError(fmt.Errorf(errSlotNumber, parts[1]))
+		return
+	}
+	details := events.Details{
+		Description: opts.value(optDescription),
+		Location:    opts.value(optLocation),
+		URL:         opts.value(optURL),
+	}
+	msg, err := c.calendar.BookSlot(c.lastFree[n-1], parts[2], events.Priority(parts[3]), details, opts.has(optForce))
 	if !c.notifyResult(msg, err) {
 		return
 	}
+	c.lastFree = nil
 }
 
-func (c *Cmd) handleAddReminderCmd(parts []string) {
-	event, err := c.selectEventsByReminder(false, parts)
+func (c *Cmd) handleTrashCmd(parts []string) {
+	sub := "list"
+	if len(parts) > 1 {
+		sub = strings.ToLower(parts[1])
+	}
+	switch {
+	case sub == "list" && len(parts) <= 2:
+		list := c.calendar.TrashedEvents()
+		c.lastTrash = c.lastTrash[:0]
+		for _, item := range list {
+			c.lastTrash = append(c.lastTrash, item.Event.ID)
+		}
+		c.handlePrint(c.calendar.FormatTrash(list))
+	case sub == "restore" && len(parts) == 3:
+		event, err := c.resolveTrashed(parts[2])
+		if !c.notifyError(err) {
+			return
+		}
+		c.notifyResult(c.calendar.RestoreFromTrash(event.ID))
+	case sub == "empty" && len(parts) == 2:
+		c.lastTrash = nil
+		c.notifyResult(c.calendar.EmptyTrash())
+	default:
+		c.handleError(errTrashFormat)
+	}
+}
+
+func (c *Cmd) handleArchiveCmd(parts []string) {
+	olderThan := c.calendar.ArchiveAfter
+	switch len(parts) {
+	case 1:
+	case 2:
+		days, err := strconv.Atoi(parts[1])
+		if err != nil || days < 0 {
+			c.handleError(errArchiveFormat)
+			return
+		}
+		olderThan = time.Duration(days) * 24 * time.Hour
+	default:
+		c.handleError(errArchiveFormat)
+		return
+	}
+	c.notifyResult(c.calendar.ArchiveEvents(olderThan))
+}
+
+func (c *Cmd) handleUndoCmd() {
+	c.notifyResult(c.calendar.Undo())
+}
+
+func (c *Cmd) handleRedoCmd() {
+	c.notifyResult(c.calendar.Redo())
+}
+
+func (c *Cmd) handleConflictsCmd(parts []string) {
+	var from, to time.Time
+	switch len(parts) {
+	case 1:
+		from = validators.Now()
+	case 3:
+		var err error
+		from, err = validators.ValidateDate(parts[1])
+		if !c.notifyError(err) {
+			return
+		}
+		to, err = validators.ValidateDate(parts[2])
+		if !c.notifyError(err) {
+			return
+		}
+	default:
+		c.handleError(errConflictsFormat)
+		return
+	}
+	c.handlePrint(c.calendar.ShowConflicts(from, to))
+}
+
+func (c *Cmd) handleShowEventCmd(parts []string) {
+	event, err := c.selectEvents(parts)
 	if !c.notifyError(err) {
 		return
 	}
-	parts, err = c.readAndParseInput(errReminderFormat)
+	c.notifyResult(c.calendar.ShowEvent(event.ID))
+}
+
+func (c *Cmd) handleTagCmd(parts []string, add bool) {
+	format := errTagFormat
+	if !add {
+		format = errUntagFormat
+	}
+	if len(parts) < 3 {
+		c.handleError(format)
+		return
+	}
+	event, err := c.selectEvents(parts)
 	if !c.notifyError(err) {
 		return
 	}
+	if add {
+		c.notifyResult(c.calendar.TagEvent(event.ID, parts[2:]))
+		return
+	}
+	c.notifyResult(c.calendar.UntagEvent(event.ID, parts[2:]))
+}
+
+func (c *Cmd) handleTagsCmd(parts []string) {
+	if len(parts) == 1 {
+		c.notifyResult(c.calendar.ShowTags())
+		return
+	}
+	if len(parts) != 4 || strings.ToLower(parts[1]) != "rename" {
+		c.handleError(errTagsFormat)
+		return
+	}
+	c.notifyResult(c.calendar.RenameTag(parts[2], parts[3]))
+}
+
+func (c *Cmd) handleAddReminderCmd(parts []string) {
+	event, err := c.selectEventsByReminder(false, parts)
+	if !c.notifyError(err) {
+		return
+	}
+	parts = parts[2:]
+	if len(parts) == 0 {
+		parts, err = c.readAndParseInput(errReminderFormat)
+		if !c.notifyError(err) {
+			return
+		}
+	}
 	if len(parts) < 2 {
-		c.handlePrint(errReminderFormat)
-		logger.LogError(errReminderFormat)
+		c.handleError(errReminderFormat)
 		return
 	}
 	message := parts[0]
@@ -199,13 +601,192 @@ func (c *Cmd) handleDeleteReminderCmd(title []string) {
 	}
 }
 
-func (c *Cmd) handleShowEventsCmd() {
+func (c *Cmd) handleImportCmd(parts []string) {
+	if len(parts) < 2 {
+		c.handleError(errImportFormat)
+		return
+	}
+	s, err := storage.Open(parts[1])
+	if !c.notifyError(err) {
+		return
+	}
+	c.notifyResult(c.calendar.ImportEvents(s))
+}
+
+func (c *Cmd) handleBackupCmd(parts []string) {
+	if len(parts) < 2 {
+		c.handleError(errBackupFormat)
+		return
+	}
+	switch strings.ToLower(parts[1]) {
+	case "list":
+		c.notifyResult(c.calendar.ListBackups())
+	case "diff":
+		if len(parts) < 3 {
+			c.handleError(errBackupFormat)
+			return
+		}
+		c.notifyResult(c.calendar.DiffBackup(parts[2]))
+	case "restore":
+		if len(parts) < 3 {
+			c.handleError(errBackupFormat)
+			return
+		}
+		if !c.notifyResult(c.calendar.DiffBackup(parts[2])) {
+			return
+		}
+		answer, err := c.readLineWithPrompt(confirmRestore)
+		if !c.notifyError(err) {
+			return
+		}
+		if !isConfirmed(answer) {
+			c.handlePrint(restoreCancelled)
+			return
+		}
+		c.notifyResult(c.calendar.RestoreBackup(parts[2]))
+	default:
+		c.handleError(errBackupFormat)
+	}
+}
+
+func isConfirmed(answer string) bool {
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "да", "д", "yes", "y":
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *Cmd) handleAttachCmd(parts []string) {
+	sub := ""
+	if len(parts) > 1 {
+		sub = strings.ToLower(parts[1])
+	}
+	valid := (sub == "add" && len(parts) == 4) || (sub == "list" && len(parts) == 3) ||
+		(sub == "get" && (len(parts) == 4 || len(parts) == 5))
+	if !valid {
+		c.handleError(errAttachFormat)
+		return
+	}
+	event, err := c.resolveEvent(parts[2], anyEvent)
+	if !c.notifyError(err) {
+		return
+	}
+	switch sub {
+	case "add":
+		c.notifyResult(c.calendar.Attach(event.ID, parts[3]))
+	case "list":
+		c.notifyResult(c.calendar.ShowAttachments(event.ID))
+	case "get":
+		dir := "."
+		if len(parts) == 5 {
+			dir = parts[4]
+		}
+		c.notifyResult(c.calendar.ExtractAttachment(event.ID, parts[3], dir))
+	}
+}
+
+func (c *Cmd) handlePackCmd(parts []string) {
+	if len(parts) < 3 {
+		c.handleError(errPackFormat)
+		return
+	}
+	err := storage.Pack(parts[1], parts[2])
+	c.notifyResult(fmt.Sprintf(packedMessage, parts[1], parts[2]), err)
+}
+
+func (c *Cmd) handleUnpackCmd(parts []string) {
+	if len(parts) < 3 {
+		c.handleError(errUnpackFormat)
+		return
+	}
+	err := storage.Unpack(parts[1], parts[2])
+	c.notifyResult(fmt.Sprintf(unpackedMessage, parts[1], parts[2]), err)
+}
+
+func (c *Cmd) handleShowEventsCmd(parts []string) {
+	args, flags, err := parseOptions(parts, listOptions, listFlags)
+	if !c.notifyError(err) {
+		return
+	}
+	if len(args) > 1 {
+		c.handleError(errListFormat)
+		return
+	}
+	var opts calendar.ListOptions
+	if flags.has(optZone) {
+		opts.SecondZone, err = validators.LoadZone(flags.value(optZone))
+		if !c.notifyError(err) {
+			return
+		}
+	}
+	opts.Tags, err = calendar.ParseTagFilter(flags[optTag], flags[optNotTag])
+	if !c.notifyError(err) {
+		return
+	}
+	opts.Sort, err = calendar.ParseSortOrder(flags.value(optSort))
+	if !c.notifyError(err) {
+		return
+	}
+	if flags.has(optFrom) {
+		opts.From, err = validators.ValidateDate(flags.value(optFrom))
+		if !c.notifyError(err) {
+			return
+		}
+	}
+	if flags.has(optTo) {
+		opts.To, err = validators.ValidateDate(flags.value(optTo))
+		if !c.notifyError(err) {
+			return
+		}
+	}
+	if flags.has(optPriority) {
+		opts.Priority, err = events.ParsePriority(flags.value(optPriority))
+		if !c.notifyError(err) {
+			return
+		}
+	}
+	opts.Archived = flags.has(optArchived)
+	list, err := c.calendar.ListEvents(opts)
+	if !c.notifyError(err) {
+		return
+	}
+	c.lastList = c.lastList[:0]
+	for _, event := range list {
+		c.lastList = append(c.lastList, event.ID)
+	}
 	c.handlePrint(eventShowMessage)
-	c.handlePrint(c.calendar.ShowEvents())
+	c.handlePrint(c.calendar.FormatEvents(list, opts))
 }
 
-func (c *Cmd) handleShowLogsCmd() {
-	fmt.Println(c.logger.showLogs())
+func (c *Cmd) handleShowLogsCmd(parts []string) {
+	args, opts, err := parseOptions(parts, historyOpts, historyFlags)
+	if !c.notifyError(err) {
+		return
+	}
+	if len(args) > 1 {
+		c.handleError(errHistoryFormat)
+		return
+	}
+	filter := HistoryFilter{
+		Grep:       opts.value(optGrep),
+		ErrorsOnly: opts.has(optErrorsOnly),
+	}
+	if opts.has(optSince) {
+		filter.Since, err = validators.ValidateDate(opts.value(optSince))
+		if !c.notifyError(err) {
+			return
+		}
+	}
+	if opts.has(optLast) {
+		filter.Last, err = strconv.Atoi(opts.value(optLast))
+		if err != nil || filter.Last <= 0 {
+			c.handleError(errHistoryFormat)
+			return
+		}
+	}
+	fmt.Println(c.logger.showLogs(filter))
 }
 
 func (c *Cmd) handleShowHelpCmd() {
@@ -214,7 +795,13 @@ func (c *Cmd) handleShowHelpCmd() {
 
 func (c *Cmd) handlePrint(msg string) {
 	fmt.Println(msg)
-	c.logger.logMessage(msg)
+	c.logger.logMessage(kindOutput, msg)
+}
+
+func (c *Cmd) handleError(msg string) {
+	fmt.Println(msg)
+	c.logger.logMessage(kindError, msg)
+	logger.Error(msg, c.logAttrs...)
 }
 
 func (c *Cmd) handleExitCmd() {
@@ -224,7 +811,201 @@ func (c *Cmd) handleExitCmd() {
 
 func (c *Cmd) handleDefaultCmd(cmd string) {
 	msg := (unknownCommand + cmd)
-	c.handlePrint(msg)
+	c.handleError(msg)
 	c.handlePrint(deafaultMessage)
-	logger.LogError(msg)
+}
+
+func (c *Cmd) handleJournalCmd(parts []string) {
+	last := 0
+	switch len(parts) {
+	case 1:
+	case 2:
+		var err error
+		last, err = strconv.Atoi(parts[1])
+		if err != nil || last <= 0 {
+			c.handleError(errJournalFormat)
+			return
+		}
+	default:
+		c.handleError(errJournalFormat)
+		return
+	}
+	c.notifyResult(c.calendar.ShowJournal(last))
+}
+
+func (c *Cmd) handleReplayCmd(parts []string) {
+	args, opts, err := parseOptions(parts, replayOptions, nil)
+	if !c.notifyError(err) {
+		return
+	}
+	if len(args) > 1 {
+		c.handleError(errReplayFormat)
+		return
+	}
+	var until time.Time
+	if opts.has(optUntil) {
+		until, err = validators.ValidateDate(opts.value(optUntil))
+		if !c.notifyError(err) {
+			return
+		}
+	}
+	var seq int64
+	if opts.has(optSeq) {
+		seq, err = strconv.ParseInt(opts.value(optSeq), 10, 64)
+		if err != nil || seq <= 0 {
+			c.handleError(errReplayFormat)
+			return
+		}
+	}
+	state, last, err := c.calendar.Replay(until, seq)
+	if !c.notifyError(err) {
+		return
+	}
+	c.handlePrint(c.calendar.ShowReplay(state, last))
+	if !opts.has(optSave) {
+		return
+	}
+	state.Storage, err = storage.Open(opts.value(optSave))
+	if !c.notifyError(err) {
+		return
+	}
+	err = state.Save()
+	if !c.notifyError(err) {
+		return
+	}
+	c.handlePrint(fmt.Sprintf(replaySaved, last.Seq, state.Storage.GetFilename()))
+}
+
+func (c *Cmd) handleTodoCmd(parts []string) {
+	args, opts, err := parseOptions(parts, todoOptions, todoFlags)
+	if !c.notifyError(err) {
+		return
+	}
+	sub := "list"
+	if len(args) > 1 {
+		sub = strings.ToLower(args[1])
+	}
+	switch {
+	case sub == "add" && len(args) == 5:
+		details := events.Details{
+			Description: opts.value(optDescription),
+			Location:    opts.value(optLocation),
+			URL:         opts.value(optURL),
+		}
+		msg, err := c.calendar.AddEvent(args[2], args[3], events.Priority(args[4]), details, calendar.AddOptions{
+			Task:     true,
+			Subtasks: opts[optSub],
+		})
+		switch {
+		case errors.Is(err, validators.ErrEmptyTitle):
+			c.handleError(errEmptyTitle)
+		case errors.Is(err, validators.ErrDateAlreadyPassed):
+			c.handleError(errPastTimeTravel)
+		default:
+			c.notifyResult(msg, err)
+		}
+	case sub == "list" && len(args) <= 2:
+		list := c.calendar.Tasks(opts.has(optAll))
+		c.lastList = c.lastList[:0]
+		for _, event := range list {
+			c.lastList = append(c.lastList, event.ID)
+		}
+		c.handlePrint(todoShowMessage)
+		c.handlePrint(c.calendar.FormatTasks(list))
+	case (sub == "check" || sub == "uncheck") && len(args) == 4:
+		event, err := c.resolveEvent(args[2], isTask)
+		if !c.notifyError(err) {
+			return
+		}
+		n, err := strconv.Atoi(args[3])
+		if err != nil {
+			c.handleError(errTodoFormat)
+			return
+		}
+		c.notifyResult(c.calendar.CheckSubtask(event.ID, n, sub == "check"))
+	case sub == "carry" && len(args) == 2:
+		c.notifyResult(c.calendar.CarryOver())
+	default:
+		c.handleError(errTodoFormat)
+	}
+}
+
+func (c *Cmd) handleDoneCmd(parts []string, done bool) {
+	if len(parts) != 2 {
+		c.handleError(errDoneFormat)
+		return
+	}
+	event, err := c.resolveEvent(parts[1], isTask)
+	if !c.notifyError(err) {
+		return
+	}
+	if done {
+		c.notifyResult(c.calendar.CompleteTask(event.ID))
+		return
+	}
+	c.notifyResult(c.calendar.ReopenTask(event.ID))
+}
+
+func (c *Cmd) handleAddFromTemplate(parts []string, opts options) {
+	if len(parts) < 2 {
+		c.handleError(errFromFormat)
+		return
+	}
+	values := make(map[string]string)
+	for _, param := range parts[2:] {
+		key, value, ok := strings.Cut(param, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			c.handleError(fmt.Sprintf(errTemplateParam, param))
+			return
+		}
+		values[strings.TrimSpace(key)] = value
+	}
+	msg, err := c.calendar.AddFromTemplate(opts.value(optFrom), parts[1], values, opts.has(optForce))
+	switch {
+	case errors.Is(err, validators.ErrEmptyTitle):
+		c.handleError(errEmptyTitle)
+	case errors.Is(err, validators.ErrDateAlreadyPassed):
+		c.handleError(errPastTimeTravel)
+	default:
+		c.notifyResult(msg, err)
+	}
+}
+
+func (c *Cmd) handleTemplateCmd(parts []string) {
+	args, opts, err := parseOptions(parts, templateOpts, nil)
+	if !c.notifyError(err) {
+		return
+	}
+	sub := "list"
+	if len(args) > 1 {
+		sub = strings.ToLower(args[1])
+	}
+	switch {
+	case sub == "save" && len(args) == 5:
+		t := calendar.Template{
+			Name:        args[2],
+			Title:       args[3],
+			Priority:    events.Priority(args[4]),
+			Duration:    opts.value(optEnd),
+			Description: opts.value(optDescription),
+			Location:    opts.value(optLocation),
+			URL:         opts.value(optURL),
+		}
+		for _, group := range opts[optTag] {
+			t.Tags = append(t.Tags, strings.Split(group, ",")...)
+		}
+		if opts.has(optRemind) {
+			t.Reminder = &calendar.TemplateReminder{Before: opts.value(optRemind), Message: opts.value(optRemindMsg)}
+			if t.Reminder.Message == "" {
+				t.Reminder.Message = t.Title
+			}
+		}
+		c.notifyResult(c.calendar.SaveTemplate(t))
+	case sub == "list" && len(args) <= 2:
+		c.notifyResult(c.calendar.ShowTemplates())
+	case sub == "delete" && len(args) == 3:
+		c.notifyResult(c.calendar.DeleteTemplate(args[2]))
+	default:
+		c.handleError(errTemplateFormat)
+	}
 }
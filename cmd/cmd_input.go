@@ -3,17 +3,34 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 
 	"github.com/google/shlex"
+	"github.com/ilsft/Golendar/calendar"
 	"github.com/ilsft/Golendar/events"
+	validators "github.com/ilsft/Golendar/utils"
 )
 
 const (
+	errUnknownOption   = "неизвестный параметр: %s"
+	errOptionValue     = "не указано значение параметра: %s"
 	errIncorrectChoice = "неверный выбор"
 	errLenEmptyTitle   = "название события не указано"
 	errNoMatchTitle    = "совпадений не найдено"
+	errNoReminder      = "у события %s нет напоминания"
+	errHasReminder     = "у события %s уже есть напоминание"
+	errNotTask         = "%s — событие, а не задача"
+	errRowNumber       = "неверный номер строки: #%s"
+	errNoLastList      = "сначала выполните list, чтобы ссылаться на строки #N"
+	errRowRange        = "строки #%d нет в последнем списке (строк: %d)"
+	errNoLastTrash     = "сначала выполните trash list, чтобы ссылаться на строки #N"
+)
+
+const (
+	minIDPrefix   = 4
+	plainIDPrefix = 8
 )
 
 func (c *Cmd) readLineWithPrompt(prompt string) (string, error) {
@@ -45,40 +62,228 @@ func (c *Cmd) readAndParseInput(promptMsg string) ([]string, error) {
 	return shlex.Split(line)
 }
 
-func (c *Cmd) titleMatches(title string, prefix string) bool {
-	return strings.HasPrefix(strings.ToLower(title), strings.ToLower(prefix))
+type options map[string][]string
+
+func (o options) has(name string) bool {
+	_, ok := o[name]
+	return ok
+}
+
+func (o options) value(name string) string {
+	values := o[name]
+	if len(values) == 0 {
+		return ""
+	}
+	return values[len(values)-1]
+}
+
+func parseOptions(parts []string, valueOpts []string, flagOpts []string) ([]string, options, error) {
+	var args []string
+	opts := make(options)
+	for i := 0; i < len(parts); i++ {
+		part := parts[i]
+		if !strings.HasPrefix(part, "--") {
+			args = append(args, part)
+			continue
+		}
+		name := strings.ToLower(strings.TrimPrefix(part, "--"))
+		switch {
+		case slices.Contains(flagOpts, name):
+			opts[name] = nil
+		case slices.Contains(valueOpts, name):
+			if i+1 >= len(parts) {
+				return nil, nil, fmt.Errorf(errOptionValue, part)
+			}
+			i++
+			opts[name] = append(opts[name], parts[i])
+		default:
+			return nil, nil, fmt.Errorf(errUnknownOption, part)
+		}
+	}
+	return args, opts, nil
+}
+
+type eventFilter func(event *events.Event) error
+
+func anyEvent(*events.Event) error {
+	return nil
+}
+
+func isTask(event *events.Event) error {
+	if !event.IsTask() {
+		return fmt.Errorf(errNotTask, event.Title)
+	}
+	return nil
+}
+
+func withReminder(event *events.Event) error {
+	if event.Reminder == nil || event.Reminder.Message == "" {
+		return fmt.Errorf(errNoReminder, event.Title)
+	}
+	return nil
+}
+
+func withoutReminder(event *events.Event) error {
+	if event.Reminder != nil && event.Reminder.Message != "" {
+		return fmt.Errorf(errHasReminder, event.Title)
+	}
+	return nil
 }
 
 func (c *Cmd) selectEvents(parts []string) (*events.Event, error) {
 	if len(parts) <= 1 {
 		return nil, errors.New(errLenEmptyTitle)
 	}
-	var matchedEvents []*events.Event
-	for _, event := range c.calendar.CalendarEvents {
-		if c.titleMatches(event.Title, parts[1]) {
-			matchedEvents = append(matchedEvents, event)
-		}
-	}
-	return c.chooseEvent(matchedEvents)
+	return c.resolveEvent(parts[1], anyEvent)
 }
 
 func (c *Cmd) selectEventsByReminder(showWithReminders bool, parts []string) (*events.Event, error) {
 	if len(parts) <= 1 {
 		return nil, errors.New(errLenEmptyTitle)
 	}
-	var matchedEvents []*events.Event
-	prefix := parts[1]
-	for _, event := range c.calendar.CalendarEvents {
-		hasReminder := event.Reminder != nil && event.Reminder.Message != ""
-		if c.titleMatches(event.Title, prefix) {
-			if showWithReminders && hasReminder {
-				matchedEvents = append(matchedEvents, event)
-			} else if !showWithReminders && !hasReminder {
-				matchedEvents = append(matchedEvents, event)
-			}
+	if showWithReminders {
+		return c.resolveEvent(parts[1], withReminder)
+	}
+	return c.resolveEvent(parts[1], withoutReminder)
+}
+
+func (c *Cmd) resolveEvent(ref string, filter eventFilter) (*events.Event, error) {
+	event, err := c.findEvent(ref, filter)
+	if event != nil {
+		c.logAttrs = append(c.logAttrs, "event_id", event.ID)
+	}
+	return event, err
+}
+
+func (c *Cmd) findEvent(ref string, filter eventFilter) (*events.Event, error) {
+	ref = strings.TrimSpace(ref)
+	if row, ok := strings.CutPrefix(ref, "#"); ok {
+		event, err := c.eventByRow(row)
+		if err != nil {
+			return nil, err
+		}
+		return event, filter(event)
+	}
+	if event, err := c.calendar.GetEventByID(ref); err == nil {
+		return event, filter(event)
+	}
+	if isIDPrefix(ref) {
+		if byID := c.calendar.EventsByIDPrefix(ref); len(byID) > 0 {
+			return c.pickEvent(byID, ref, filter)
+		}
+	}
+
+	candidates, err := c.calendar.EventsByTitlePrefix(ref)
+	if err != nil {
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		for _, r := range c.calendar.Search(ref, calendar.SearchSubstring) {
+			candidates = append(candidates, r.Event)
+		}
+	}
+	return c.pickEvent(candidates, ref, filter)
+}
+
+func (c *Cmd) pickEvent(candidates []*events.Event, ref string, filter eventFilter) (*events.Event, error) {
+	var matched []*events.Event
+	var lastErr error
+	for _, event := range candidates {
+		if err := filter(event); err != nil {
+			lastErr = err
+			continue
+		}
+		matched = append(matched, event)
+	}
+	switch len(matched) {
+	case 0:
+		if lastErr != nil && len(candidates) == 1 {
+			return nil, lastErr
+		}
+		return nil, errors.New(errNoMatchTitle)
+	case 1:
+		return matched[0], nil
+	}
+	var exact []*events.Event
+	for _, event := range matched {
+		if strings.EqualFold(event.Title, ref) {
+			exact = append(exact, event)
+		}
+	}
+	if len(exact) == 1 {
+		return exact[0], nil
+	}
+	return c.chooseEvent(matched)
+}
+
+func (c *Cmd) eventByRow(row string) (*events.Event, error) {
+	id, err := rowID(row, c.lastList, errNoLastList)
+	if err != nil {
+		return nil, err
+	}
+	return c.calendar.GetEventByID(id)
+}
+
+func rowID(row string, ids []string, errEmpty string) (string, error) {
+	n, err := strconv.Atoi(row)
+	if err != nil {
+		return "", fmt.Errorf(errRowNumber, row)
+	}
+	if len(ids) == 0 {
+		return "", errors.New(errEmpty)
+	}
+	if n < 1 || n > len(ids) {
+		return "", fmt.Errorf(errRowRange, n, len(ids))
+	}
+	return ids[n-1], nil
+}
+
+func (c *Cmd) resolveTrashed(ref string) (*events.Event, error) {
+	ref = strings.TrimSpace(ref)
+	if row, ok := strings.CutPrefix(ref, "#"); ok {
+		id, err := rowID(row, c.lastTrash, errNoLastTrash)
+		if err != nil {
+			return nil, err
+		}
+		item, ok := c.calendar.Trash[id]
+		if !ok {
+			return nil, errors.New(errNoMatchTitle)
+		}
+		return item.Event, nil
+	}
+	var candidates []*events.Event
+	for _, item := range c.calendar.TrashedEvents() {
+		event := item.Event
+		if event.ID == ref {
+			return event, nil
+		}
+		byID := isIDPrefix(ref) && strings.HasPrefix(event.ID, strings.ToLower(ref))
+		if byID || validators.HasWordPrefix(event.Title, ref) {
+			candidates = append(candidates, event)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, errors.New(errNoMatchTitle)
+	}
+	return c.pickEvent(candidates, ref, anyEvent)
+}
+
+func isIDPrefix(ref string) bool {
+	if len(ref) < minIDPrefix {
+		return false
+	}
+	var digits, letters bool
+	for _, r := range ref {
+		switch {
+		case strings.ContainsRune("0123456789", r):
+			digits = true
+		case strings.ContainsRune("abcdefABCDEF", r):
+			letters = true
+		case r != '-':
+			return false
 		}
 	}
-	return c.chooseEvent(matchedEvents)
+	return len(ref) >= plainIDPrefix || (digits && letters)
 }
 
 func (c *Cmd) chooseEvent(matchedEvents []*events.Event) (*events.Event, error) {
@@ -86,7 +291,7 @@ func (c *Cmd) chooseEvent(matchedEvents []*events.Event) (*events.Event, error)
 		return nil, errors.New(errNoMatchTitle)
 	}
 	for i, event := range matchedEvents {
-		fmt.Printf("%d. %s - %s\n", i+1, event.Title, event.StartAt.Format(patternTime))
+		fmt.Printf("%d. %s - %s\n", i+1, event.Title, c.calendar.FormatDate(event.StartAt))
 	}
 	choice, err := c.getUserChoice(len(matchedEvents))
 	if err != nil {
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ilsft/Golendar/calendar"
+	"github.com/ilsft/Golendar/events"
+	"github.com/ilsft/Golendar/reminder"
+)
+
+func newTestCmd(input string) *Cmd {
+	c := calendar.NewCalendar(nil)
+	base := time.Date(2030, 1, 1, 10, 0, 0, 0, time.UTC)
+	c.CalendarEvents["1eeddedb-0000"] = &events.Event{ID: "1eeddedb-0000", Title: "Встреча с командой", StartAt: base}
+	c.CalendarEvents["5037dc27-0000"] = &events.Event{ID: "5037dc27-0000", Title: "Встреча с клиентом", StartAt: base.Add(time.Hour),
+		Reminder: &reminder.Reminder{Message: "позвонить"}}
+	c.CalendarEvents["b1660199-0000"] = &events.Event{ID: "b1660199-0000", Title: "Турнир", StartAt: base.Add(2 * time.Hour)}
+	return &Cmd{
+		calendar: c,
+		logger:   NewHistoryLogger(nil),
+		reader:   bufio.NewReader(strings.NewReader(input)),
+	}
+}
+
+func TestResolveEventWithoutPrompt(t *testing.T) {
+	c := newTestCmd("")
+	c.lastList = []string{"b1660199-0000", "1eeddedb-0000"}
+	cases := map[string]string{
+		"1eeddedb-0000": "1eeddedb-0000",
+		"5037dc":        "5037dc27-0000",
+		"#1":            "b1660199-0000",
+		"турн":          "b1660199-0000",
+		"клиент":        "5037dc27-0000",
+	}
+	for ref, want := range cases {
+		event, err := c.resolveEvent(ref, anyEvent)
+		if err != nil || event.ID != want {
+			t.Errorf("%q: ожидалось %s, получено %v, %v", ref, want, event, err)
+		}
+	}
+	if event, err := c.resolveEvent("встреча", withReminder); err != nil || event.ID != "5037dc27-0000" {
+		t.Errorf("Фильтр по напоминанию должен оставить одно событие: %v, %v", event, err)
+	}
+	if _, err := c.resolveEvent("#5", anyEvent); err == nil {
+		t.Error("Ожидалась ошибка номера строки")
+	}
+}
+
+func TestResolveEventIgnoresFuzzyAndWordPrefixes(t *testing.T) {
+	c := newTestCmd("")
+	c.calendar.CalendarEvents["beef0000-0000"] = &events.Event{ID: "beef0000-0000", Title: "Обед"}
+	c.calendar.CalendarEvents["20250000-0000"] = &events.Event{ID: "20250000-0000", Title: "Отчёт"}
+	c.calendar.CalendarEvents["a0000000-0000"] = &events.Event{ID: "a0000000-0000", Title: "beef stroganoff"}
+	c.calendar.CalendarEvents["a1000000-0000"] = &events.Event{ID: "a1000000-0000", Title: "План 2025"}
+
+	if _, err := c.resolveEvent("турнри", anyEvent); err == nil {
+		t.Error("Опечатка не должна находить событие без подтверждения")
+	}
+	for ref, want := range map[string]string{"beef": "a0000000-0000", "2025": "a1000000-0000"} {
+		event, err := c.resolveEvent(ref, anyEvent)
+		if err != nil || event.ID != want {
+			t.Errorf("%q должно искаться по названию, а не по ID: %v, %v", ref, event, err)
+		}
+	}
+}
+
+func TestResolveEventPromptsWhenAmbiguous(t *testing.T) {
+	c := newTestCmd("2\n")
+	event, err := c.resolveEvent("встреча", anyEvent)
+	if err != nil || event.ID != "5037dc27-0000" {
+		t.Errorf("Ожидался выбор второго события: %v, %v", event, err)
+	}
+}
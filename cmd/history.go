@@ -3,6 +3,8 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"regexp"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -10,18 +12,40 @@ import (
 	"github.com/ilsft/Golendar/storage"
 )
 
+const (
+	kindInput  = "input"
+	kindOutput = "output"
+	kindError  = "error"
+)
+
+const promptHistorySize = 200
+
+const emptyHistory = "история пуста"
+
 type HistoryEntry struct {
 	Time    time.Time `json:"time"`
+	Kind    string    `json:"kind,omitempty"`
 	Message string    `json:"message"`
 }
 
 type HistoryLogger struct {
-	Logs    []HistoryEntry
-	Storage storage.Store
+	Logs       []HistoryEntry
+	Storage    storage.Store
+	MaxEntries int           `json:"-"`
+	MaxAge     time.Duration `json:"-"`
+}
+
+type HistoryFilter struct {
+	Since      time.Time
+	Grep       string
+	Last       int
+	ErrorsOnly bool
 }
 
 var mu sync.Mutex
 
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
 func NewHistoryLogger(s storage.Store) *HistoryLogger {
 	return &HistoryLogger{
 		Logs:    make([]HistoryEntry, 0),
@@ -29,12 +53,13 @@ func NewHistoryLogger(s storage.Store) *HistoryLogger {
 	}
 }
 
-func (hl *HistoryLogger) logMessage(message string) {
+func (hl *HistoryLogger) logMessage(kind, message string) {
 	mu.Lock()
 	defer mu.Unlock()
 	entry := HistoryEntry{
 		Time:    time.Now(),
-		Message: message,
+		Kind:    kind,
+		Message: ansiEscape.ReplaceAllString(message, ""),
 	}
 	hl.Logs = append(hl.Logs, entry)
 }
@@ -54,10 +79,14 @@ func (hl *HistoryLogger) loadLogs() error {
 	if err != nil {
 		return (err)
 	}
+	hl.rotate()
 	return nil
 }
 
 func (hl *HistoryLogger) saveLogs() error {
+	mu.Lock()
+	defer mu.Unlock()
+	hl.rotate()
 	data, err := json.Marshal(hl)
 	if err != nil {
 		return (err)
@@ -69,14 +98,63 @@ func (hl *HistoryLogger) saveLogs() error {
 	return nil
 }
 
-func (hl *HistoryLogger) showLogs() string {
-	if len(hl.Logs) == 0 {
-		return ""
+func (hl *HistoryLogger) rotate() {
+	if hl.MaxAge > 0 {
+		deadline := time.Now().Add(-hl.MaxAge)
+		i := 0
+		for i < len(hl.Logs) && hl.Logs[i].Time.Before(deadline) {
+			i++
+		}
+		hl.Logs = hl.Logs[i:]
+	}
+	if hl.MaxEntries > 0 && len(hl.Logs) > hl.MaxEntries {
+		hl.Logs = slices.Clone(hl.Logs[len(hl.Logs)-hl.MaxEntries:])
+	}
+}
+
+func (hl *HistoryLogger) inputs() []string {
+	var result []string
+	for _, entry := range hl.Logs {
+		if entry.Kind != kindInput || strings.TrimSpace(entry.Message) == "" {
+			continue
+		}
+		if n := len(result); n > 0 && result[n-1] == entry.Message {
+			continue
+		}
+		result = append(result, entry.Message)
+	}
+	if len(result) > promptHistorySize {
+		result = result[len(result)-promptHistorySize:]
 	}
+	return result
+}
+
+func (hl *HistoryLogger) showLogs(f HistoryFilter) string {
+	mu.Lock()
+	defer mu.Unlock()
+	grep := strings.ToLower(f.Grep)
 	var logs []string
 	for _, entry := range hl.Logs {
-		log := (fmt.Sprintf("%s - %s", entry.Time.Format(patternTime), entry.Message))
-		logs = append(logs, log)
+		if !f.Since.IsZero() && entry.Time.Before(f.Since) {
+			continue
+		}
+		if f.ErrorsOnly && entry.Kind != kindError {
+			continue
+		}
+		if grep != "" && !strings.Contains(strings.ToLower(entry.Message), grep) {
+			continue
+		}
+		message := entry.Message
+		if entry.Kind == kindInput {
+			message = "> " + message
+		}
+		logs = append(logs, fmt.Sprintf("%s - %s", entry.Time.Format(patternTime), message))
+	}
+	if f.Last > 0 && len(logs) > f.Last {
+		logs = logs[len(logs)-f.Last:]
+	}
+	if len(logs) == 0 {
+		return emptyHistory
 	}
 	return strings.Join(logs, "\n")
 }
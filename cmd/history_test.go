@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHistoryFilterAndRotate(t *testing.T) {
+	now := time.Now()
+	hl := &HistoryLogger{
+		Logs: []HistoryEntry{
+			{Time: now.Add(-48 * time.Hour), Kind: kindInput, Message: "list"},
+			{Time: now.Add(-2 * time.Hour), Kind: kindInput, Message: "add \"Встреча\""},
+			{Time: now.Add(-2 * time.Hour), Kind: kindError, Message: "неверный формат"},
+			{Time: now.Add(-time.Hour), Kind: kindInput, Message: "add \"Встреча\""},
+			{Time: now.Add(-time.Hour), Kind: kindOutput, Message: "Событие: Встреча добавлено"},
+		},
+		MaxEntries: 4,
+		MaxAge:     24 * time.Hour,
+	}
+
+	if got := hl.showLogs(HistoryFilter{ErrorsOnly: true}); !strings.Contains(got, "неверный формат") || strings.Contains(got, "Встреча") {
+		t.Errorf("--errors-only должен оставить только ошибки, получено:\n%s", got)
+	}
+	if got := hl.showLogs(HistoryFilter{Grep: "встреча", Last: 1}); strings.Count(got, "\n") != 0 || !strings.Contains(got, "добавлено") {
+		t.Errorf("--grep и --last 1 должны вернуть последнюю подходящую запись, получено:\n%s", got)
+	}
+	if got := hl.showLogs(HistoryFilter{Since: now.Add(-3 * time.Hour)}); strings.Contains(got, "> list") {
+		t.Errorf("--since должен отбросить старые записи, получено:\n%s", got)
+	}
+
+	hl.rotate()
+	if len(hl.Logs) != 4 || hl.Logs[0].Message == "list" {
+		t.Errorf("Ротация должна удалить записи старше суток, осталось %d", len(hl.Logs))
+	}
+	if inputs := hl.inputs(); len(inputs) != 1 || inputs[0] != "add \"Встреча\"" {
+		t.Errorf("Повторы команд подряд должны схлопываться, получено %q", inputs)
+	}
+}
+
+func TestHistoryStripsColors(t *testing.T) {
+	hl := NewHistoryLogger(nil)
+	hl.logMessage(kindOutput, "#1 Турнир - \x1b[31mhigh\x1b[0m - \x1b[1mтурнир\x1b[0m")
+	if got := hl.Logs[0].Message; got != "#1 Турнир - high - турнир" {
+		t.Errorf("Цветовые коды не должны попадать в историю, получено %q", got)
+	}
+}
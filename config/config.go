@@ -0,0 +1,144 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ilsft/Golendar/calendar"
+	"github.com/ilsft/Golendar/events"
+	"github.com/ilsft/Golendar/logger"
+	"github.com/ilsft/Golendar/storage"
+	validators "github.com/ilsft/Golendar/utils"
+)
+
+var (
+	errorParseConfig = "ошибка чтения конфигурации %s: %v"
+	errArchiveConfig = errors.New("archive.uri должен содержать {year}, а after_days не может быть отрицательным")
+	errBackupConfig  = errors.New("backup.hourly и backup.daily должны быть положительными")
+	errHistoryConfig = errors.New("history.max_entries и history.max_age не могут быть отрицательными")
+)
+
+type Config struct {
+	Backup     BackupConfig            `json:"backup"`
+	Display    DisplayConfig           `json:"display"`
+	Validation validators.DatePolicies `json:"validation"`
+	Title      validators.TitlePolicy  `json:"title"`
+	Priorities events.PriorityLevels   `json:"priorities"`
+	Schedule   ScheduleConfig          `json:"schedule"`
+	Trash      TrashConfig             `json:"trash"`
+	Archive    ArchiveConfig           `json:"archive"`
+	History    HistoryConfig           `json:"history"`
+	Log        logger.Config           `json:"log"`
+	Tasks      TasksConfig             `json:"tasks"`
+}
+
+type BackupConfig struct {
+	Enabled bool   `json:"enabled"`
+	Dir     string `json:"dir"`
+	Hourly  int    `json:"hourly"`
+	Daily   int    `json:"daily"`
+}
+
+type DisplayConfig struct {
+	TimeZone string `json:"time_zone"`
+}
+
+type TrashConfig struct {
+	Retention Duration `json:"retention"`
+}
+
+const (
+	DefaultHistoryEntries = 1000
+	DefaultHistoryAge     = 90 * 24 * time.Hour
+)
+
+type TasksConfig struct {
+	CarryOver bool `json:"carry_over"`
+}
+
+type HistoryConfig struct {
+	MaxEntries int      `json:"max_entries"`
+	MaxAge     Duration `json:"max_age"`
+}
+
+type ArchiveConfig struct {
+	URI       string `json:"uri"`
+	AfterDays int    `json:"after_days"`
+	Auto      bool   `json:"auto"`
+}
+
+func Default() *Config {
+	return &Config{
+		Backup: BackupConfig{
+			Enabled: true,
+			Dir:     "backups",
+			Hourly:  24,
+			Daily:   30,
+		},
+		Validation: validators.DefaultDatePolicies(),
+		Title:      validators.DefaultTitlePolicy(),
+		Schedule:   DefaultSchedule(),
+		Trash: TrashConfig{
+			Retention: Duration(calendar.DefaultTrashRetention),
+		},
+		Archive: ArchiveConfig{
+			URI:       "zip://archive/calendar-{year}.zip",
+			AfterDays: 365,
+		},
+		History: HistoryConfig{
+			MaxEntries: DefaultHistoryEntries,
+			MaxAge:     Duration(DefaultHistoryAge),
+		},
+		Log: logger.DefaultConfig(),
+	}
+}
+
+func Load(s storage.Store) (*Config, error) {
+	cfg := Default()
+	data, err := s.Load()
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+	if len(data) == 0 {
+		return cfg, nil
+	}
+	err = json.Unmarshal(data, cfg)
+	if err == nil {
+		err = cfg.Validation.Validate()
+	}
+	if err == nil {
+		err = cfg.Title.Validate()
+	}
+	if err == nil {
+		err = cfg.Priorities.Validate()
+	}
+	if err == nil {
+		err = cfg.Schedule.Validate()
+	}
+	if err == nil && (cfg.Archive.AfterDays < 0 || !strings.Contains(cfg.Archive.URI, "{year}")) {
+		err = errArchiveConfig
+	}
+	if err == nil {
+		err = cfg.Log.Validate()
+	}
+	if err == nil && cfg.Backup.Enabled && (cfg.Backup.Hourly <= 0 || cfg.Backup.Daily <= 0) {
+		err = errBackupConfig
+	}
+	if err == nil && (cfg.History.MaxEntries < 0 || cfg.History.MaxAge < 0) {
+		err = errHistoryConfig
+	}
+	if err == nil && cfg.Trash.Retention < 0 {
+		err = fmt.Errorf(errorDuration, time.Duration(cfg.Trash.Retention), errNegative)
+	}
+	if err != nil {
+		return Default(), fmt.Errorf(errorParseConfig, s.GetFilename(), err)
+	}
+	return cfg, nil
+}
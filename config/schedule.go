@@ -0,0 +1,78 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ilsft/Golendar/calendar"
+	"github.com/ilsft/Golendar/events"
+)
+
+var (
+	errorDuration  = "неверная длительность %q: %w"
+	errNotPositive = errors.New("длительность должна быть положительной")
+	errNegative    = errors.New("длительность не может быть отрицательной")
+	errSlots       = errors.New("число свободных окон должно быть положительным")
+)
+
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	err := json.Unmarshal(data, &s)
+	if err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf(errorDuration, s, err)
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+type ScheduleConfig struct {
+	DefaultDuration Duration              `json:"default_duration"`
+	BufferBefore    Duration              `json:"buffer_before"`
+	BufferAfter     Duration              `json:"buffer_after"`
+	WorkingHours    calendar.WorkingHours `json:"working_hours"`
+	Slots           int                   `json:"slots"`
+}
+
+func DefaultSchedule() ScheduleConfig {
+	return ScheduleConfig{
+		DefaultDuration: Duration(events.DefaultDuration),
+		WorkingHours:    calendar.DefaultWorkingHours(),
+		Slots:           calendar.DefaultSlots,
+	}
+}
+
+func (s ScheduleConfig) Validate() error {
+	if s.DefaultDuration <= 0 {
+		return fmt.Errorf(errorDuration, time.Duration(s.DefaultDuration), errNotPositive)
+	}
+	for _, d := range []Duration{s.BufferBefore, s.BufferAfter} {
+		if d < 0 {
+			return fmt.Errorf(errorDuration, time.Duration(d), errNegative)
+		}
+	}
+	if s.Slots < 1 {
+		return errSlots
+	}
+	return s.WorkingHours.Validate()
+}
+
+func (s ScheduleConfig) Schedule() calendar.Schedule {
+	return calendar.Schedule{
+		WorkingHours: s.WorkingHours,
+		BufferBefore: time.Duration(s.BufferBefore),
+		BufferAfter:  time.Duration(s.BufferAfter),
+		Slots:        s.Slots,
+	}
+}
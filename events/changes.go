@@ -0,0 +1,94 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+
+	validators "github.com/ilsft/Golendar/utils"
+)
+
+type Changes struct {
+	Title    *string
+	Date     *string
+	End      *string
+	Priority *Priority
+	Details  *Details
+}
+
+func (ch Changes) IsEmpty() bool {
+	return ch.Title == nil && ch.Date == nil && ch.End == nil && ch.Priority == nil && ch.Details == nil
+}
+
+func (e *Event) Apply(ch Changes, policy validators.DatePolicy) error {
+	title := e.Title
+	if ch.Title != nil {
+		normalized, err := validators.ValidateTitle(*ch.Title)
+		if errors.Is(err, validators.ErrEmptyTitle) {
+			return err
+		}
+		if err != nil {
+			return fmt.Errorf(errTitlePattern, *ch.Title, err)
+		}
+		title = normalized
+	}
+
+	startAt := e.StartAt
+	if ch.Date != nil {
+		t, err := validators.ValidateDate(*ch.Date)
+		if err != nil {
+			return fmt.Errorf(errorValidEvent, err, title)
+		}
+		if !t.Equal(e.StartAt) {
+			err = policy.Check(t)
+			if err != nil {
+				return fmt.Errorf(errorValidEvent, err, title)
+			}
+		}
+		startAt = t
+	}
+
+	endAt := e.EndAt
+	switch {
+	case ch.End != nil && *ch.End == "":
+		endAt = nil
+	case ch.End != nil:
+		t, err := ParseEnd(*ch.End, startAt)
+		if err != nil {
+			return err
+		}
+		endAt = &t
+	case endAt != nil:
+		t := endAt.Add(startAt.Sub(e.StartAt)).In(startAt.Location())
+		endAt = &t
+	}
+
+	priority := e.Priority
+	if ch.Priority != nil {
+		p, err := ParsePriority(string(*ch.Priority))
+		if err != nil {
+			return fmt.Errorf(errorValidEvent, err, title)
+		}
+		priority = p
+	}
+
+	details := e.Details()
+	if ch.Details != nil {
+		valid, err := ch.Details.Validate()
+		if err != nil {
+			return err
+		}
+		details = valid
+	}
+
+	e.Title = title
+	if ch.Date != nil {
+		e.StartAt = startAt
+		e.TimeZone = validators.ZoneName(startAt.Location())
+	}
+	e.EndAt = endAt
+	e.Priority = priority
+	e.Description = details.Description
+	e.Location = details.Location
+	e.URL = details.URL
+	return nil
+}
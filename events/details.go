@@ -0,0 +1,72 @@
+package events
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	validators "github.com/ilsft/Golendar/utils"
+)
+
+const (
+	maxDescriptionLength = 2000
+	maxLocationLength    = 200
+	maxURLLength         = 2048
+)
+
+const (
+	errFieldTooLong = "поле %s длиннее %d символов"
+	errorValidField = "ошибка в поле %s: %w"
+)
+
+type Details struct {
+	Description string
+	Location    string
+	URL         string
+}
+
+func (d Details) Validate() (Details, error) {
+	d.Description = strings.TrimSpace(d.Description)
+	d.Location = strings.TrimSpace(d.Location)
+	d.URL = strings.TrimSpace(d.URL)
+
+	for _, f := range []struct {
+		name  string
+		value string
+		max   int
+	}{
+		{"description", d.Description, maxDescriptionLength},
+		{"location", d.Location, maxLocationLength},
+		{"url", d.URL, maxURLLength},
+	} {
+		if utf8.RuneCountInString(f.value) > f.max {
+			return Details{}, fmt.Errorf(errFieldTooLong, f.name, f.max)
+		}
+	}
+	if d.URL != "" {
+		err := validators.ValidateURL(d.URL)
+		if err != nil {
+			return Details{}, fmt.Errorf(errorValidField, "url", err)
+		}
+	}
+	return d, nil
+}
+
+func (e *Event) Details() Details {
+	return Details{
+		Description: e.Description,
+		Location:    e.Location,
+		URL:         e.URL,
+	}
+}
+
+func (e *Event) SetDetails(d Details) error {
+	valid, err := d.Validate()
+	if err != nil {
+		return err
+	}
+	e.Description = valid.Description
+	e.Location = valid.Location
+	e.URL = valid.URL
+	return nil
+}
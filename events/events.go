@@ -1,7 +1,9 @@
 package events
 
 import (
+	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,7 +12,7 @@ import (
 )
 
 const (
-	errTitlePattern    = "неверное имя в событии: %s"
+	errTitlePattern    = "неверное имя в событии %q: %w"
 	errorValidEvent    = "ошибка %w в событии: %s"
 	ErrorValidReminder = "ошибка %w в напоминании: %s"
 )
@@ -19,49 +21,104 @@ type Event struct {
 	ID       string             `json:"id"`
 	Title    string             `json:"title"`
 	StartAt  time.Time          `json:"start_at"`
+	EndAt    *time.Time         `json:"end_at,omitempty"`
+	TimeZone string             `json:"time_zone,omitempty"`
 	Priority Priority           `json:"priority"`
 	Reminder *reminder.Reminder `json:"reminder"`
+
+	Description string `json:"description,omitempty"`
+	Location    string `json:"location,omitempty"`
+	URL         string `json:"url,omitempty"`
+
+	Tags []string `json:"tags,omitempty"`
+
+	Task *TaskInfo `json:"task,omitempty"`
 }
 
 func getNextID() string {
 	return uuid.New().String()
 }
 
-func NewEvent(title string, dateStr string, priority Priority) (*Event, error) {
-	err := validators.CheckTitleEmpty(title)
+func NewEvent(title string, dateStr string, priority Priority, policy validators.DatePolicy) (*Event, error) {
+	title, err := checkTitle(title)
 	if err != nil {
 		return nil, err
 	}
-	if !validators.IsValidTitle(title) {
-		return nil, fmt.Errorf(errTitlePattern, title)
-	}
 	t, err := validators.ValidateDate(dateStr)
 	if err != nil {
 		return nil, fmt.Errorf(errorValidEvent, err, title)
 	}
+	return buildEvent(title, t, priority, policy)
+}
+
+func NewEventAt(title string, start time.Time, priority Priority, policy validators.DatePolicy) (*Event, error) {
+	title, err := checkTitle(title)
+	if err != nil {
+		return nil, err
+	}
+	return buildEvent(title, start, priority, policy)
+}
+
+func checkTitle(title string) (string, error) {
+	normalized, err := validators.ValidateTitle(title)
+	if errors.Is(err, validators.ErrEmptyTitle) {
+		return "", err
+	}
+	if err != nil {
+		return "", fmt.Errorf(errTitlePattern, title, err)
+	}
+	return normalized, nil
+}
 
-	errPriority := priority.ValidatePriority()
-	if errPriority != nil {
-		return nil, fmt.Errorf(errorValidEvent, errPriority, title)
+func buildEvent(title string, t time.Time, priority Priority, policy validators.DatePolicy) (*Event, error) {
+	priority, err := ParsePriority(string(priority))
+	if err != nil {
+		return nil, fmt.Errorf(errorValidEvent, err, title)
+	}
+	err = policy.Check(t)
+	if err != nil {
+		return nil, fmt.Errorf(errorValidEvent, err, title)
 	}
 
 	return &Event{
 		ID:       getNextID(),
 		Title:    title,
 		StartAt:  t,
+		TimeZone: validators.ZoneName(t.Location()),
 		Priority: priority,
 		Reminder: nil,
 	}, nil
 }
 
-func (e *Event) Update(title string, date string, priority Priority) error {
-	validEvent, err := NewEvent(title, date, priority)
+func (e *Event) Update(title string, date string, priority Priority, policy validators.DatePolicy) error {
+	return e.Apply(Changes{Title: &title, Date: &date, Priority: &priority}, policy)
+}
+
+func (e *Event) Zone() *time.Location {
+	loc, err := validators.LoadZone(e.TimeZone)
 	if err != nil {
-		return err
+		return time.Local
+	}
+	return loc
+}
+
+func (e *Event) Restore() {
+	if p, err := ParsePriority(string(e.Priority)); err == nil {
+		e.Priority = p
+	}
+	if tags, err := NormalizeTags(e.Tags); err == nil && len(tags) > 0 {
+		e.Tags = tags
+	}
+	slices.Sort(e.Tags)
+	e.Tags = slices.Compact(e.Tags)
+	e.StartAt = e.StartAt.In(e.Zone())
+	if e.EndAt != nil {
+		end := e.EndAt.In(e.Zone())
+		e.EndAt = &end
+	}
+	if e.Reminder != nil {
+		e.Reminder.At = e.Reminder.At.In(e.Zone())
 	}
-	e.Title = validEvent.Title
-	e.StartAt = validEvent.StartAt
-	return nil
 }
 
 func (e *Event) AddReminder(message string, at time.Time, notifier reminder.Notifier) (string, error) {
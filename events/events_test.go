@@ -0,0 +1,161 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	validators "github.com/ilsft/Golendar/utils"
+)
+
+func TestPastEventPolicy(t *testing.T) {
+	_, err := NewEvent("встреча", "2020-12-12 10:00", PriorityLow, validators.DateFutureOnly)
+	if !errors.Is(err, validators.ErrDateAlreadyPassed) {
+		t.Errorf("Ожидалась ошибка прошедшей даты, получено: %v", err)
+	}
+
+	e, err := NewEvent("встреча", "2020-12-12 10:00", PriorityLow, validators.DateAllowPast)
+	if err != nil {
+		t.Fatalf("Прошедшее событие должно создаваться: %v", err)
+	}
+	err = e.Update("итоги встречи", "2020-12-12 10:00", PriorityLow, validators.DateFutureOnly)
+	if err != nil || e.Title != "итоги встречи" {
+		t.Errorf("Изменение без смены даты должно проходить: %v", err)
+	}
+	err = e.Update("итоги встречи", "2020-12-13 10:00", PriorityLow, validators.DateFutureOnly)
+	if !errors.Is(err, validators.ErrDateAlreadyPassed) {
+		t.Errorf("Перенос в прошлое должен запрещаться: %v", err)
+	}
+}
+
+func TestDetailsValidationAndRoundTrip(t *testing.T) {
+	e, err := NewEvent("планёрка", "2030-01-01 10:00", PriorityMedium, validators.DateFutureOnly)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.SetDetails(Details{URL: "meet/abc"}); err == nil {
+		t.Error("Ожидалась ошибка неверной ссылки")
+	}
+	if err := e.SetDetails(Details{Description: strings.Repeat("а", 2001)}); err == nil {
+		t.Error("Ожидалась ошибка длины описания")
+	}
+	want := Details{Description: "повестка", Location: "Переговорная 3", URL: "https://meet.example.com/abc"}
+	if err := e.SetDetails(want); err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Event
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Details() != want {
+		t.Errorf("Ожидалось %+v, получено %+v", want, got.Details())
+	}
+}
+
+func TestApplyPartialChanges(t *testing.T) {
+	e, err := NewEvent("встреча", "2030-01-01 10:00", PriorityLow, validators.DateFutureOnly)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := e.StartAt
+	high := PriorityHigh
+	if err := e.Apply(Changes{Priority: &high}, validators.DateFutureOnly); err != nil {
+		t.Fatal(err)
+	}
+	if e.Priority != PriorityHigh || e.Title != "встреча" || !e.StartAt.Equal(start) {
+		t.Errorf("Должен измениться только приоритет: %+v", e)
+	}
+
+	bad := "x"
+	date := "2031-01-01 10:00"
+	err = e.Apply(Changes{Title: &bad, Date: &date}, validators.DateFutureOnly)
+	if err == nil {
+		t.Fatal("Ожидалась ошибка неверного имени")
+	}
+	if e.Title != "встреча" || !e.StartAt.Equal(start) {
+		t.Errorf("При ошибке событие не должно меняться: %+v", e)
+	}
+}
+
+func TestParsePriority(t *testing.T) {
+	for input, want := range map[string]Priority{
+		"High": PriorityHigh, "h": PriorityHigh, "высокий": PriorityHigh,
+		" M ": PriorityMedium, "средний": PriorityMedium,
+		"l": PriorityLow, "НИЗКИЙ": PriorityLow,
+	} {
+		got, err := ParsePriority(input)
+		if err != nil || got != want {
+			t.Errorf("%q: ожидалось %s, получено %s (%v)", input, want, got, err)
+		}
+	}
+	if _, err := ParsePriority("срочно"); err == nil {
+		t.Error("Ожидалась ошибка неизвестного приоритета")
+	}
+}
+
+func TestCustomPriorityLevels(t *testing.T) {
+	defer SetPriorityLevels(nil)
+	err := SetPriorityLevels(PriorityLevels{
+		{Name: "Critical", Rank: 40, Color: "magenta", Aliases: []string{"crit"}},
+		{Name: "someday", Rank: 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := ParsePriority("CRIT")
+	if err != nil || p != "critical" {
+		t.Fatalf("Ожидался critical, получено %s (%v)", p, err)
+	}
+	if !(p.Rank() > PriorityHigh.Rank() && Priority("someday").Rank() < PriorityLow.Rank()) {
+		t.Error("Неверный порядок пользовательских уровней")
+	}
+	if _, err := ParsePriority("high"); err != nil {
+		t.Errorf("Встроенные уровни должны оставаться: %v", err)
+	}
+	if err := (PriorityLevels{{Name: "urgent", Aliases: []string{"h"}}}).Validate(); err == nil {
+		t.Error("Ожидалась ошибка повторного обозначения")
+	}
+	if err := (PriorityLevels{{Name: "urgent", Color: "pink"}}).Validate(); err == nil {
+		t.Error("Ожидалась ошибка неизвестного цвета")
+	}
+	if PriorityHigh.Colorize() == string(PriorityHigh) {
+		t.Error("Ожидался цветной приоритет")
+	}
+	SetColor(false)
+	defer SetColor(true)
+	if got := PriorityHigh.Colorize(); got != string(PriorityHigh) {
+		t.Errorf("Без терминала приоритет выводится без цвета, получено %q", got)
+	}
+}
+
+func TestRestoreNormalizesTags(t *testing.T) {
+	e := &Event{Title: "Планёрка", Tags: []string{"Work", " #work ", "Client:ACME"}}
+	e.Restore()
+	if strings.Join(e.Tags, ",") != "client:acme,work" || !e.HasTag("work") {
+		t.Errorf("Загруженные теги должны нормализоваться, получено %q", e.Tags)
+	}
+}
+
+func TestParseEndTimeOnStartDay(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip(err)
+	}
+	start := time.Date(2026, 10, 24, 15, 0, 0, 0, berlin)
+	end, err := ParseEnd("17:00", start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2026, 10, 24, 17, 0, 0, 0, berlin); !end.Equal(want) || end.Location() != berlin {
+		t.Errorf("Время окончания должно относиться к дню начала: ожидалось %v, получено %v", want, end)
+	}
+	if _, err := ParseEnd("14:00", start); err == nil {
+		t.Error("Ожидалась ошибка окончания раньше начала")
+	}
+}
@@ -0,0 +1,64 @@
+package events
+
+import (
+	"fmt"
+	"time"
+
+	validators "github.com/ilsft/Golendar/utils"
+)
+
+const DefaultDuration = time.Hour
+
+const (
+	errEndBeforeStart = "окончание %s должно быть позже начала %s"
+	errEndDuration    = "длительность %s должна быть положительной"
+	errorValidEnd     = "ошибка в окончании события: %w"
+)
+
+func ParseEnd(value string, start time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		if d <= 0 {
+			return time.Time{}, fmt.Errorf(errEndDuration, value)
+		}
+		return start.Add(d), nil
+	}
+	t, ok, err := validators.ParseClock(value, start)
+	if !ok || err != nil {
+		t, err = validators.ValidateDate(value)
+		if err != nil {
+			return time.Time{}, fmt.Errorf(errorValidEnd, err)
+		}
+	}
+	if !t.After(start) {
+		return time.Time{}, fmt.Errorf(errEndBeforeStart,
+			validators.FormatDateEvent(t), validators.FormatDateEvent(start))
+	}
+	return t.In(start.Location()), nil
+}
+
+func (e *Event) SetEnd(value string) error {
+	if value == "" {
+		e.EndAt = nil
+		return nil
+	}
+	t, err := ParseEnd(value, e.StartAt)
+	if err != nil {
+		return err
+	}
+	e.EndAt = &t
+	return nil
+}
+
+func (e *Event) End(defaultDuration time.Duration) time.Time {
+	if e.EndAt != nil {
+		return *e.EndAt
+	}
+	return e.StartAt.Add(defaultDuration)
+}
+
+func (e *Event) Overlaps(other *Event, defaultDuration time.Duration) bool {
+	if e.IsTask() || other.IsTask() {
+		return false
+	}
+	return e.StartAt.Before(other.End(defaultDuration)) && other.StartAt.Before(e.End(defaultDuration))
+}
@@ -2,10 +2,21 @@ package events
 
 import (
 	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
 )
 
 const errPriority = "неверный приоритет"
 
+var (
+	errPriorityName  = errors.New("пустое имя уровня приоритета")
+	errPriorityColor = "неизвестный цвет %q у приоритета %s"
+	errPriorityAlias = "обозначение %q используется в приоритетах %s и %s"
+	errPriorityValue = "%s: %q"
+)
+
 type Priority string
 
 const (
@@ -14,11 +25,141 @@ const (
 	PriorityHigh   Priority = "high"
 )
 
+const colorReset = "\033[0m"
+
+var colorOutput = true
+
+var priorityColors = map[string]string{
+	"red":     "\033[31m",
+	"green":   "\033[32m",
+	"yellow":  "\033[33m",
+	"blue":    "\033[34m",
+	"magenta": "\033[35m",
+	"cyan":    "\033[36m",
+	"white":   "\033[37m",
+	"gray":    "\033[90m",
+}
+
+type PriorityLevel struct {
+	Name    string   `json:"name"`
+	Rank    int      `json:"rank"`
+	Color   string   `json:"color,omitempty"`
+	Aliases []string `json:"aliases,omitempty"`
+}
+
+type PriorityLevels []PriorityLevel
+
+var (
+	priorityLevels = DefaultPriorityLevels()
+	priorityLookup = mustLookup(priorityLevels)
+)
+
+func DefaultPriorityLevels() PriorityLevels {
+	return PriorityLevels{
+		{Name: string(PriorityHigh), Rank: 30, Color: "red", Aliases: []string{"h", "высокий", "в"}},
+		{Name: string(PriorityMedium), Rank: 20, Color: "yellow", Aliases: []string{"m", "med", "средний", "с"}},
+		{Name: string(PriorityLow), Rank: 10, Color: "green", Aliases: []string{"l", "низкий", "н"}},
+	}
+}
+
+func SetPriorityLevels(custom PriorityLevels) error {
+	levels := mergeLevels(custom)
+	lookup, err := levels.lookup()
+	if err != nil {
+		return err
+	}
+	priorityLevels = levels
+	priorityLookup = lookup
+	return nil
+}
+
+func (l PriorityLevels) Validate() error {
+	_, err := mergeLevels(l).lookup()
+	return err
+}
+
+func SetColor(enabled bool) {
+	colorOutput = enabled
+}
+
+func ColorEnabled() bool {
+	return colorOutput
+}
+
+func Levels() PriorityLevels {
+	return priorityLevels
+}
+
+func mergeLevels(custom PriorityLevels) PriorityLevels {
+	levels := DefaultPriorityLevels()
+	for _, level := range custom {
+		level.Name = normalizePriority(level.Name)
+		i := slices.IndexFunc(levels, func(l PriorityLevel) bool { return l.Name == level.Name })
+		if i >= 0 {
+			levels[i] = level
+		} else {
+			levels = append(levels, level)
+		}
+	}
+	sort.SliceStable(levels, func(i, j int) bool {
+		return levels[i].Rank > levels[j].Rank
+	})
+	return levels
+}
+
+func (l PriorityLevels) lookup() (map[string]PriorityLevel, error) {
+	lookup := make(map[string]PriorityLevel)
+	for _, level := range l {
+		if level.Name == "" {
+			return nil, errPriorityName
+		}
+		if _, ok := priorityColors[level.Color]; level.Color != "" && !ok {
+			return nil, fmt.Errorf(errPriorityColor, level.Color, level.Name)
+		}
+		for _, key := range append([]string{level.Name}, level.Aliases...) {
+			key = normalizePriority(key)
+			if other, ok := lookup[key]; ok && other.Name != level.Name {
+				return nil, fmt.Errorf(errPriorityAlias, key, other.Name, level.Name)
+			}
+			lookup[key] = level
+		}
+	}
+	return lookup, nil
+}
+
+func mustLookup(levels PriorityLevels) map[string]PriorityLevel {
+	lookup, err := levels.lookup()
+	if err != nil {
+		panic(err)
+	}
+	return lookup
+}
+
+func normalizePriority(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+func ParsePriority(s string) (Priority, error) {
+	level, ok := priorityLookup[normalizePriority(s)]
+	if !ok {
+		return "", fmt.Errorf(errPriorityValue, errPriority, s)
+	}
+	return Priority(level.Name), nil
+}
+
 func (p Priority) ValidatePriority() error {
-	switch p {
-	case PriorityLow, PriorityMedium, PriorityHigh:
-		return nil
-	default:
-		return errors.New(errPriority)
+	_, err := ParsePriority(string(p))
+	return err
+}
+
+func (p Priority) Rank() int {
+	return priorityLookup[normalizePriority(string(p))].Rank
+}
+
+func (p Priority) Colorize() string {
+	color, ok := priorityColors[priorityLookup[normalizePriority(string(p))].Color]
+	if !ok || !colorOutput {
+		return string(p)
 	}
+	return color + string(p) + colorReset
 }
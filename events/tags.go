@@ -0,0 +1,104 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const maxTagLength = 50
+
+var (
+	errEmptyTag   = "пустой тег"
+	errTagChar    = "недопустимый символ %q в теге %s"
+	errTagTooLong = "тег %s длиннее %d символов"
+)
+
+func NormalizeTag(tag string) (string, error) {
+	tag = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(tag), "#")))
+	if tag == "" {
+		return "", errors.New(errEmptyTag)
+	}
+	if utf8.RuneCountInString(tag) > maxTagLength {
+		return "", fmt.Errorf(errTagTooLong, tag, maxTagLength)
+	}
+	for _, r := range tag {
+		if !unicode.IsLetter(r) && !unicode.IsNumber(r) && !strings.ContainsRune(":-_/.", r) {
+			return "", fmt.Errorf(errTagChar, r, tag)
+		}
+	}
+	return tag, nil
+}
+
+func NormalizeTags(tags []string) ([]string, error) {
+	result := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		normalized, err := NormalizeTag(tag)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, normalized)
+	}
+	return result, nil
+}
+
+func (e *Event) HasTag(tag string) bool {
+	_, found := slices.BinarySearch(e.Tags, tag)
+	return found
+}
+
+func (e *Event) AddTags(tags ...string) ([]string, error) {
+	normalized, err := NormalizeTags(tags)
+	if err != nil {
+		return nil, err
+	}
+	var added []string
+	for _, tag := range normalized {
+		i, found := slices.BinarySearch(e.Tags, tag)
+		if found {
+			continue
+		}
+		e.Tags = slices.Insert(e.Tags, i, tag)
+		added = append(added, tag)
+	}
+	return added, nil
+}
+
+func (e *Event) RemoveTags(tags ...string) ([]string, error) {
+	normalized, err := NormalizeTags(tags)
+	if err != nil {
+		return nil, err
+	}
+	var removed []string
+	for _, tag := range normalized {
+		i, found := slices.BinarySearch(e.Tags, tag)
+		if !found {
+			continue
+		}
+		e.Tags = slices.Delete(e.Tags, i, i+1)
+		removed = append(removed, tag)
+	}
+	if len(e.Tags) == 0 {
+		e.Tags = nil
+	}
+	return removed, nil
+}
+
+func (e *Event) RenameTag(oldTag string, newTag string) bool {
+	if !e.HasTag(oldTag) {
+		return false
+	}
+	e.RemoveTags(oldTag)
+	e.AddTags(newTag)
+	return true
+}
+
+func (e *Event) FormatTags() string {
+	if len(e.Tags) == 0 {
+		return ""
+	}
+	return "#" + strings.Join(e.Tags, " #")
+}
@@ -0,0 +1,107 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	validators "github.com/ilsft/Golendar/utils"
+)
+
+var (
+	ErrNotTask         = errors.New("это событие, а не задача")
+	errAlreadyDone     = errors.New("задача уже выполнена")
+	errNotDone         = errors.New("задача ещё не выполнена")
+	errorSubtaskNumber = "нет подзадачи с номером %d, всего подзадач: %d"
+	errorSubtaskTitle  = "неверное имя подзадачи %q: %w"
+)
+
+type TaskInfo struct {
+	Done        bool       `json:"done"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	Subtasks    []Subtask  `json:"subtasks,omitempty"`
+}
+
+type Subtask struct {
+	Title string `json:"title"`
+	Done  bool   `json:"done"`
+}
+
+func (t *TaskInfo) IsDone() bool {
+	return t != nil && t.Done
+}
+
+func (e *Event) IsTask() bool {
+	return e.Task != nil
+}
+
+func (e *Event) MakeTask(subtasks []string) error {
+	task := &TaskInfo{}
+	for _, title := range subtasks {
+		normalized, err := validators.ValidateTitle(title)
+		if err != nil {
+			return fmt.Errorf(errorSubtaskTitle, title, err)
+		}
+		task.Subtasks = append(task.Subtasks, Subtask{Title: normalized})
+	}
+	e.Task = task
+	e.EndAt = nil
+	return nil
+}
+
+func (e *Event) Complete(at time.Time) error {
+	if !e.IsTask() {
+		return ErrNotTask
+	}
+	if e.Task.Done {
+		return errAlreadyDone
+	}
+	e.Task.Done = true
+	e.Task.CompletedAt = &at
+	e.Reminder.Stop()
+	return nil
+}
+
+func (e *Event) Reopen() error {
+	if !e.IsTask() {
+		return ErrNotTask
+	}
+	if !e.Task.Done {
+		return errNotDone
+	}
+	e.Task.Done = false
+	e.Task.CompletedAt = nil
+	return nil
+}
+
+func (e *Event) CheckSubtask(n int, done bool) error {
+	if !e.IsTask() {
+		return ErrNotTask
+	}
+	if n < 1 || n > len(e.Task.Subtasks) {
+		return fmt.Errorf(errorSubtaskNumber, n, len(e.Task.Subtasks))
+	}
+	e.Task.Subtasks[n-1].Done = done
+	return nil
+}
+
+func (e *Event) Overdue(now time.Time) bool {
+	return e.IsTask() && !e.Task.Done && e.StartAt.Before(now)
+}
+
+func (e *Event) Progress() string {
+	if !e.IsTask() || len(e.Task.Subtasks) == 0 {
+		return ""
+	}
+	done := 0
+	for _, s := range e.Task.Subtasks {
+		if s.Done {
+			done++
+		}
+	}
+	return fmt.Sprintf("%d/%d", done, len(e.Task.Subtasks))
+}
+
+func (e *Event) ReminderDue() bool {
+	return e.Reminder != nil && !e.Task.IsDone()
+}
@@ -7,6 +7,8 @@ require (
 	github.com/c-bata/go-prompt v0.2.6
 	github.com/google/shlex v0.0.0-20191202100458-e7afc7fbc510
 	github.com/google/uuid v1.6.0
+	github.com/rivo/uniseg v0.1.0
+	golang.org/x/text v0.27.0
 )
 
 require (
@@ -15,6 +17,5 @@ require (
 	github.com/mattn/go-runewidth v0.0.10 // indirect
 	github.com/mattn/go-tty v0.0.3 // indirect
 	github.com/pkg/term v1.2.0-beta.2 // indirect
-	github.com/rivo/uniseg v0.1.0 // indirect
 	golang.org/x/sys v0.0.0-20200918174421-af09f7315aff // indirect
 )
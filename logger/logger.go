@@ -1,29 +1,104 @@
 package logger
 
 import (
-	"log"
-	"os"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
 )
 
 var (
-	infoLogger  *log.Logger
-	errorLogger *log.Logger
+	errorLevel  = "неизвестный уровень журнала %q, доступны: debug, info, warn, error"
+	errorFormat = "неизвестный формат журнала %q, доступны: text, json"
+	errLogSize  = errors.New("log.max_size_mb и log.max_backups не могут быть отрицательными")
 )
 
-func StartLogger(filename string) (*os.File, error) {
-	logFile, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+type Config struct {
+	File       string `json:"file"`
+	Level      string `json:"level"`
+	Format     string `json:"format"`
+	MaxSizeMB  int    `json:"max_size_mb"`
+	MaxBackups int    `json:"max_backups"`
+}
+
+var log = slog.New(slog.DiscardHandler)
+
+func DefaultConfig() Config {
+	return Config{
+		File:       "app.log",
+		Level:      "info",
+		Format:     FormatText,
+		MaxSizeMB:  10,
+		MaxBackups: 3,
+	}
+}
+
+func (c Config) Validate() error {
+	_, err := parseLevel(c.Level)
+	if err != nil {
+		return err
+	}
+	switch strings.ToLower(c.Format) {
+	case FormatText, FormatJSON:
+	default:
+		return fmt.Errorf(errorFormat, c.Format)
+	}
+	if c.MaxSizeMB < 0 || c.MaxBackups < 0 {
+		return errLogSize
+	}
+	return nil
+}
+
+func parseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	err := level.UnmarshalText([]byte(s))
+	if err != nil {
+		return level, fmt.Errorf(errorLevel, s)
+	}
+	return level, nil
+}
+
+func Start(cfg Config) (io.Closer, error) {
+	err := cfg.Validate()
+	if err != nil {
+		return nil, err
+	}
+	level, _ := parseLevel(cfg.Level)
+	file, err := openRotating(cfg.File, int64(cfg.MaxSizeMB)<<20, cfg.MaxBackups)
 	if err != nil {
 		return nil, err
 	}
-	infoLogger = log.New(logFile, "INFO: ", log.Ldate|log.Ltime|log.Lshortfile)
-	errorLogger = log.New(logFile, "ERROR: ", log.Ldate|log.Ltime|log.Lshortfile)
-	return logFile, nil
+	opts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler = slog.NewTextHandler(file, opts)
+	if strings.ToLower(cfg.Format) == FormatJSON {
+		handler = slog.NewJSONHandler(file, opts)
+	}
+	log = slog.New(handler)
+	return file, nil
+}
+
+func With(args ...any) *slog.Logger {
+	return log.With(args...)
+}
+
+func Debug(msg string, args ...any) {
+	log.Debug(msg, args...)
+}
+
+func Info(msg string, args ...any) {
+	log.Info(msg, args...)
 }
 
-func LogInfo(msg string) {
-	infoLogger.Output(2, msg)
+func Warn(msg string, args ...any) {
+	log.Warn(msg, args...)
 }
 
-func LogError(msg string) {
-	errorLogger.Output(2, msg)
+func Error(msg string, args ...any) {
+	log.Error(msg, args...)
 }
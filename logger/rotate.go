@@ -0,0 +1,84 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+type rotatingFile struct {
+	mu      sync.Mutex
+	name    string
+	maxSize int64
+	backups int
+	file    *os.File
+	size    int64
+}
+
+func openRotating(name string, maxSize int64, backups int) (*rotatingFile, error) {
+	r := &rotatingFile{name: name, maxSize: maxSize, backups: backups}
+	err := r.open()
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *rotatingFile) open() error {
+	file, err := os.OpenFile(r.name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	r.file = file
+	r.size = info.Size()
+	return nil
+}
+
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.maxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		err := r.rotate()
+		if err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+func (r *rotatingFile) rotate() error {
+	err := r.file.Close()
+	if err != nil {
+		return err
+	}
+	if r.backups == 0 {
+		err = os.Remove(r.name)
+	} else {
+		os.Remove(backupName(r.name, r.backups))
+		for i := r.backups - 1; i >= 1; i-- {
+			os.Rename(backupName(r.name, i), backupName(r.name, i+1))
+		}
+		err = os.Rename(r.name, backupName(r.name, 1))
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return r.open()
+}
+
+func (r *rotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Close()
+}
+
+func backupName(name string, i int) string {
+	return fmt.Sprintf("%s.%d", name, i)
+}
@@ -0,0 +1,38 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRotationKeepsBackups(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "app.log")
+	r, err := openRotating(name, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	for _, line := range []string{"первая\n", "вторая\n", "третья\n", "четвёртая\n"} {
+		if _, err := r.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := map[string]string{name: "четвёртая", name + ".1": "третья", name + ".2": "вторая"}
+	for file, text := range want {
+		data, err := os.ReadFile(file)
+		if err != nil || !strings.Contains(string(data), text) {
+			t.Errorf("В %s ожидалось %q, получено %q (%v)", filepath.Base(file), text, data, err)
+		}
+	}
+	if _, err := os.Stat(name + ".3"); !os.IsNotExist(err) {
+		t.Error("Лишние копии журнала должны удаляться")
+	}
+}
+
+func TestNoopBeforeStart(t *testing.T) {
+	Info("до инициализации", "command", "list")
+	Error("до инициализации")
+}
@@ -1,31 +1,141 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
+	"os"
+	"os/user"
+	"time"
+	_ "time/tzdata"
 
 	"github.com/ilsft/Golendar/calendar"
 	"github.com/ilsft/Golendar/cmd"
+	"github.com/ilsft/Golendar/config"
+	"github.com/ilsft/Golendar/events"
 	"github.com/ilsft/Golendar/logger"
 	"github.com/ilsft/Golendar/storage"
+	validators "github.com/ilsft/Golendar/utils"
 )
 
 func main() {
-	s := storage.NewJsonStorage("calendar.json")
+	storageURI := flag.String("storage", "file://calendar.json", "хранилище календаря, например gzip+checksum+file:///path/calendar.json.gz")
+	historyURI := flag.String("history", "file://iohistory.json", "хранилище истории ввода/вывода")
+	journalURI := flag.String("journal", "file://journal.json", "хранилище журнала изменений календаря")
+	configPath := flag.String("config", "config.json", "файл конфигурации")
+	flag.Parse()
+
+	cfg, configErr := config.Load(storage.NewJsonStorage(*configPath))
+	if configErr != nil {
+		fmt.Println(configErr.Error())
+	}
+
+	logFile, err := logger.Start(cfg.Log)
+	if err != nil {
+		fmt.Println(err.Error())
+	} else {
+		defer logFile.Close()
+	}
+	if configErr != nil {
+		logger.Warn(configErr.Error(), "config", *configPath)
+	}
+
+	err = validators.SetTitlePolicy(cfg.Title)
+	if err != nil {
+		fmt.Println(err.Error())
+	}
+	err = events.SetPriorityLevels(cfg.Priorities)
+	if err != nil {
+		fmt.Println(err.Error())
+	}
+	events.SetColor(isTerminal(os.Stdout) && os.Getenv("NO_COLOR") == "")
+
+	s, err := storage.Open(*storageURI)
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	if cfg.Backup.Enabled {
+		s = storage.NewBackupStorage(s, cfg.Backup.Dir, cfg.Backup.Hourly, cfg.Backup.Daily)
+	}
 	c := calendar.NewCalendar(s)
-	err := c.Load()
+	c.Policies = cfg.Validation
+	c.DefaultDuration = time.Duration(cfg.Schedule.DefaultDuration)
+	c.Schedule = cfg.Schedule.Schedule()
+	c.TrashRetention = time.Duration(cfg.Trash.Retention)
+	c.OpenArchive = calendar.ArchiveURI(cfg.Archive.URI)
+	c.ArchiveAfter = time.Duration(cfg.Archive.AfterDays) * 24 * time.Hour
+	c.DisplayZone, err = validators.LoadZone(cfg.Display.TimeZone)
 	if err != nil {
 		fmt.Println(err.Error())
 	}
 
-	file, err := logger.StartLogger("app.log")
+	journalStorage, err := storage.Open(*journalURI)
 	if err != nil {
 		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	c.Journal = calendar.NewJournal(journalStorage)
+	c.Journal.Actor = currentUser()
+	err = c.Journal.Load()
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+
+	err = c.Load()
+	if err != nil {
+		fmt.Println(err.Error())
+		logger.Error(err.Error(), "storage", *storageURI)
+		if errors.Is(err, calendar.ErrNewerSchema) {
+			os.Exit(1)
+		}
+	}
+	if err == nil && cfg.Archive.Auto {
+		msg, err := c.ArchiveEvents(c.ArchiveAfter)
+		if err == nil {
+			err = c.Save()
+		}
+		if err != nil {
+			msg = err.Error()
+		}
+		fmt.Println(msg)
+	}
+	if err == nil && cfg.Tasks.CarryOver && len(c.Overdue()) > 0 {
+		msg, err := c.CarryOver()
+		if err == nil {
+			err = c.Save()
+		}
+		if err != nil {
+			msg = err.Error()
+		}
+		fmt.Println(msg)
+	} else if msg := c.ShowOverdue(); err == nil && msg != "" {
+		fmt.Println(msg)
 	}
-	defer file.Close()
 
-	historyStorage := storage.NewJsonStorage("iohistory.json")
+	historyStorage, err := storage.Open(*historyURI)
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
 	historyLogger := cmd.NewHistoryLogger(historyStorage)
+	historyLogger.MaxEntries = cfg.History.MaxEntries
+	historyLogger.MaxAge = time.Duration(cfg.History.MaxAge)
 
 	cli := cmd.NewCmd(c, historyLogger)
 	cli.Run()
 }
+
+func currentUser() string {
+	u, err := user.Current()
+	if err != nil {
+		return os.Getenv("USER")
+	}
+	return u.Username
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
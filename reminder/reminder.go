@@ -9,7 +9,7 @@ import (
 )
 
 const (
-	invalidNameRemMsg           = "неверное имя"
+	invalidNameRemMsg           = "неверное имя: %w"
 	alreadySentRemMsg           = "напоминание уже отправлено!"
 	sentRemMsg                  = "напоминание: %s"
 	passedTimeRemMsg            = "время напоминания уже прошло"
@@ -23,6 +23,7 @@ type Reminder struct {
 	Message  string      `json:"message"`
 	At       time.Time   `json:"time"`
 	Sent     bool        `json:"sent"`
+	Stopped  bool        `json:"stopped,omitempty"`
 	timer    *time.Timer `json:"-"`
 	notifier Notifier    `json:"-"`
 }
@@ -32,13 +33,14 @@ type Notifier interface {
 }
 
 func NewReminder(message string, at time.Time, notifier Notifier) (*Reminder, error) {
-	err := validators.CheckTitleEmpty(message)
-	if err != nil {
+	normalized, err := validators.ValidateTitle(message)
+	if errors.Is(err, validators.ErrEmptyTitle) {
 		return nil, err
 	}
-	if !validators.IsValidTitle(message) {
-		return nil, errors.New(invalidNameRemMsg)
+	if err != nil {
+		return nil, fmt.Errorf(invalidNameRemMsg, err)
 	}
+	message = normalized
 
 	return &Reminder{
 		Message:  message,
@@ -81,3 +83,22 @@ func (r *Reminder) Stop() string {
 		return fmt.Sprintf(remTimerExpiredOrStoppedMsg, r.Message)
 	}
 }
+
+func (r *Reminder) Pause() string {
+	msg := r.Stop()
+	if r != nil {
+		r.Stopped = true
+	}
+	return msg
+}
+
+func (r *Reminder) Rearm(notifier Notifier) string {
+	r.notifier = notifier
+	if r.Sent {
+		return fmt.Sprintf(sentRemMsg, r.Message)
+	}
+	if r.Stopped {
+		return fmt.Sprintf(remTimerStoppedMsg, r.Message)
+	}
+	return r.Start()
+}
@@ -0,0 +1,191 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	SnapshotHourly = "hourly"
+	SnapshotDaily  = "daily"
+
+	hourlyLayout   = "20060102-15"
+	dailyLayout    = "20060102"
+	snapshotSuffix = ".json"
+)
+
+var (
+	errSnapshotNotFound = "снимок %s не найден"
+	errSnapshotID       = "неверный идентификатор снимка: %s"
+)
+
+type Snapshot struct {
+	ID   string
+	Kind string
+	Time time.Time
+	Size int64
+}
+
+type BackupStorage struct {
+	Store
+	dir    string
+	hourly int
+	daily  int
+	now    func() time.Time
+}
+
+func NewBackupStorage(s Store, dir string, hourly int, daily int) *BackupStorage {
+	return &BackupStorage{
+		Store:  s,
+		dir:    dir,
+		hourly: hourly,
+		daily:  daily,
+		now:    time.Now,
+	}
+}
+
+func (b *BackupStorage) Unwrap() Store {
+	return b.Store
+}
+
+func (b *BackupStorage) Save(data []byte) error {
+	err := b.snapshot()
+	if err != nil {
+		return err
+	}
+	return b.Store.Save(data)
+}
+
+func (b *BackupStorage) SaveRecords(meta []byte, upserts map[string]json.RawMessage, deletes []string) error {
+	err := b.snapshot()
+	if err != nil {
+		return err
+	}
+	return saveRecords(b.Store, meta, upserts, deletes)
+}
+
+func (b *BackupStorage) snapshot() error {
+	now := b.now()
+	missing := make([]string, 0, 2)
+	for _, id := range []string{
+		SnapshotHourly + "-" + now.Format(hourlyLayout),
+		SnapshotDaily + "-" + now.Format(dailyLayout),
+	} {
+		_, err := os.Stat(b.snapshotPath(id))
+		if errors.Is(err, os.ErrNotExist) {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	current, err := b.Store.Load()
+	if errors.Is(err, os.ErrNotExist) || (err == nil && len(current) == 0) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(b.dir, 0755)
+	if err != nil {
+		return err
+	}
+	for _, id := range missing {
+		err = os.WriteFile(b.snapshotPath(id), current, 0644)
+		if err != nil {
+			return err
+		}
+	}
+	return b.prune()
+}
+
+func (b *BackupStorage) prune() error {
+	snapshots, err := b.List()
+	if err != nil {
+		return err
+	}
+	kept := map[string]int{}
+	limits := map[string]int{SnapshotHourly: b.hourly, SnapshotDaily: b.daily}
+	for _, s := range snapshots {
+		kept[s.Kind]++
+		if limits[s.Kind] <= 0 || kept[s.Kind] <= limits[s.Kind] {
+			continue
+		}
+		err = os.Remove(b.snapshotPath(s.ID))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *BackupStorage) List() ([]Snapshot, error) {
+	entries, err := os.ReadDir(b.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var snapshots []Snapshot
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), snapshotSuffix)
+		if !ok || entry.IsDir() {
+			continue
+		}
+		kind, at, err := parseSnapshotID(id)
+		if err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, Snapshot{ID: id, Kind: kind, Time: at, Size: info.Size()})
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Time.After(snapshots[j].Time)
+	})
+	return snapshots, nil
+}
+
+func (b *BackupStorage) LoadSnapshot(id string) ([]byte, error) {
+	_, _, err := parseSnapshotID(id)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(b.snapshotPath(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf(errSnapshotNotFound, id)
+	}
+	return data, err
+}
+
+func (b *BackupStorage) snapshotPath(id string) string {
+	return filepath.Join(b.dir, id+snapshotSuffix)
+}
+
+func parseSnapshotID(id string) (string, time.Time, error) {
+	kind, stamp, _ := strings.Cut(id, "-")
+	layout := ""
+	switch kind {
+	case SnapshotHourly:
+		layout = hourlyLayout
+	case SnapshotDaily:
+		layout = dailyLayout
+	default:
+		return "", time.Time{}, fmt.Errorf(errSnapshotID, id)
+	}
+	at, err := time.ParseInLocation(layout, stamp, time.Local)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf(errSnapshotID, id)
+	}
+	return kind, at, nil
+}
@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+const checksumMarker = "\nsha256:"
+
+var (
+	errChecksumMissing  = errors.New("контрольная сумма отсутствует")
+	errChecksumMismatch = "контрольная сумма не совпадает: ожидалось %s, получено %s"
+)
+
+type ChecksumStorage struct {
+	Store
+}
+
+func NewChecksumStorage(s Store) *ChecksumStorage {
+	return &ChecksumStorage{s}
+}
+
+func (c *ChecksumStorage) Unwrap() Store {
+	return c.Store
+}
+
+func (c *ChecksumStorage) Save(data []byte) error {
+	sum := sha256.Sum256(data)
+	out := make([]byte, 0, len(data)+len(checksumMarker)+sha256.Size*2)
+	out = append(out, data...)
+	out = append(out, checksumMarker...)
+	out = append(out, hex.EncodeToString(sum[:])...)
+	return c.Store.Save(out)
+}
+
+func (c *ChecksumStorage) Load() ([]byte, error) {
+	data, err := c.Store.Load()
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return data, nil
+	}
+	i := bytes.LastIndex(data, []byte(checksumMarker))
+	if i < 0 {
+		return nil, errChecksumMissing
+	}
+	payload := data[:i]
+	want := string(data[i+len(checksumMarker):])
+	sum := sha256.Sum256(payload)
+	got := hex.EncodeToString(sum[:])
+	if want != got {
+		return nil, fmt.Errorf(errChecksumMismatch, want, got)
+	}
+	return payload, nil
+}
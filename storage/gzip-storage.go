@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+)
+
+type GzipStorage struct {
+	Store
+}
+
+func NewGzipStorage(s Store) *GzipStorage {
+	return &GzipStorage{s}
+}
+
+func (g *GzipStorage) Unwrap() Store {
+	return g.Store
+}
+
+func (g *GzipStorage) Save(data []byte) error {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, err := zw.Write(data)
+	if err != nil {
+		zw.Close()
+		return err
+	}
+	err = zw.Close()
+	if err != nil {
+		return err
+	}
+	return g.Store.Save(buf.Bytes())
+}
+
+func (g *GzipStorage) Load() ([]byte, error) {
+	data, err := g.Store.Load()
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return data, nil
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	return io.ReadAll(zr)
+}
@@ -0,0 +1,805 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	validators "github.com/ilsft/Golendar/utils"
+)
+
+const (
+	indexFile         = "index.json"
+	dataFilePattern   = "events-%d.dat"
+	recordFilePattern = "records-%d.idx"
+	eventsField       = "events"
+
+	// indexFormat 1 adds the body hash, the end time and the task flags
+	// to every record.
+	indexFormat = 1
+	// compactMinGarbage keeps small data files from being rewritten after
+	// every few edits.
+	compactMinGarbage = 64 << 10
+)
+
+const (
+	recordTask byte = 1 << iota
+	recordDone
+)
+
+var (
+	errIndexCorrupted = "индекс повреждён: запись %s вне файла данных"
+	errRecordsFile    = "индекс повреждён: %v"
+)
+
+type indexRecord struct {
+	ID       string
+	Offset   int64
+	Length   int64
+	Hash     uint64
+	Title    string
+	StartAt  time.Time
+	EndAt    time.Time
+	Priority string
+	Flags    byte
+}
+
+type indexFileData struct {
+	Format      int             `json:"format"`
+	DataFile    string          `json:"data_file"`
+	RecordsFile string          `json:"records_file"`
+	Generation  int64           `json:"generation"`
+	Garbage     int64           `json:"garbage"`
+	Meta        json.RawMessage `json:"meta"`
+}
+
+type indexedFields struct {
+	Title    string     `json:"title"`
+	StartAt  time.Time  `json:"start_at"`
+	EndAt    *time.Time `json:"end_at"`
+	Priority string     `json:"priority"`
+	Task     *struct {
+		Done bool `json:"done"`
+	} `json:"task"`
+}
+
+// IndexStorage keeps every event as a separate record in an append-only
+// data file. Changed events are appended on save and the space of their
+// old versions is reclaimed once it outgrows the live data.
+type IndexStorage struct {
+	*Storage
+	mu         sync.Mutex
+	loaded     bool
+	file       indexFileData
+	byStart    []indexRecord
+	byID       map[string]int
+	tokens     map[string][]int
+	tokenList  []string
+	byPriority map[string][]int
+}
+
+func NewIndexStorage(dir string) *IndexStorage {
+	return &IndexStorage{
+		Storage: &Storage{filename: dir},
+	}
+}
+
+func (s *IndexStorage) Save(data []byte) error {
+	meta, evs, err := splitDocument(data)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err = s.ensureLoaded()
+	if err != nil {
+		return err
+	}
+	var deletes []string
+	for _, r := range s.byStart {
+		if _, ok := evs[r.ID]; !ok {
+			deletes = append(deletes, r.ID)
+		}
+	}
+	return s.apply(meta, evs, deletes)
+}
+
+// SaveRecords stores the document without events and writes only the
+// given events; records that did not change are skipped.
+func (s *IndexStorage) SaveRecords(meta []byte, upserts map[string]json.RawMessage, deletes []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.ensureLoaded()
+	if err != nil {
+		return err
+	}
+	return s.apply(meta, upserts, deletes)
+}
+
+func (s *IndexStorage) Load() ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.ensureLoaded()
+	if err != nil {
+		return nil, err
+	}
+	if s.file.Generation == 0 {
+		return []byte{}, nil
+	}
+
+	raw, err := s.readRecords(s.byStart)
+	if err != nil {
+		return nil, err
+	}
+	evs := make(map[string]json.RawMessage, len(raw))
+	for i, r := range s.byStart {
+		evs[r.ID] = raw[i]
+	}
+	doc := make(map[string]json.RawMessage)
+	if meta := s.meta(); len(meta) != 0 {
+		err = json.Unmarshal(meta, &doc)
+		if err != nil {
+			return nil, err
+		}
+	}
+	doc[eventsField], err = json.Marshal(evs)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(doc)
+}
+
+// Meta returns the saved document without its events.
+func (s *IndexStorage) Meta() ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.ensureLoaded()
+	if err != nil {
+		return nil, err
+	}
+	return s.meta(), nil
+}
+
+func (s *IndexStorage) All() ([]json.RawMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.ensureLoaded()
+	if err != nil {
+		return nil, err
+	}
+	return s.readRecords(s.byStart)
+}
+
+func (s *IndexStorage) Len() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.ensureLoaded()
+	if err != nil {
+		return 0, err
+	}
+	return len(s.byStart), nil
+}
+
+func (s *IndexStorage) Get(ids ...string) ([]json.RawMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.ensureLoaded()
+	if err != nil {
+		return nil, err
+	}
+	s.buildIDs()
+	var positions []int
+	for _, id := range ids {
+		if pos, ok := s.byID[id]; ok {
+			positions = append(positions, pos)
+		}
+	}
+	return s.readPositions(positions)
+}
+
+func (s *IndexStorage) Range(from time.Time, to time.Time) ([]json.RawMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.ensureLoaded()
+	if err != nil {
+		return nil, err
+	}
+	lo := sort.Search(len(s.byStart), func(i int) bool {
+		return !s.byStart[i].StartAt.Before(from)
+	})
+	hi := sort.Search(len(s.byStart), func(i int) bool {
+		return s.byStart[i].StartAt.After(to)
+	})
+	if lo >= hi {
+		return nil, nil
+	}
+	return s.readRecords(s.byStart[lo:hi])
+}
+
+// Overlapping returns events that are not tasks and intersect [from, to).
+// Events without an end last d; a zero to leaves the range open.
+func (s *IndexStorage) Overlapping(from time.Time, to time.Time, d time.Duration) ([]json.RawMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.ensureLoaded()
+	if err != nil {
+		return nil, err
+	}
+	hi := len(s.byStart)
+	if !to.IsZero() {
+		hi = sort.Search(len(s.byStart), func(i int) bool {
+			return !s.byStart[i].StartAt.Before(to)
+		})
+	}
+	var positions []int
+	for pos, r := range s.byStart[:hi] {
+		end := r.EndAt
+		if end.IsZero() {
+			end = r.StartAt.Add(d)
+		}
+		if r.Flags&recordTask == 0 && end.After(from) {
+			positions = append(positions, pos)
+		}
+	}
+	return s.readPositions(positions)
+}
+
+func (s *IndexStorage) TitlePrefix(prefix string) ([]json.RawMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.ensureLoaded()
+	if err != nil {
+		return nil, err
+	}
+	words := validators.Tokenize(prefix)
+	if len(words) == 0 {
+		return nil, nil
+	}
+	s.buildTokens()
+
+	first := words[0]
+	seen := make(map[int]bool)
+	var positions []int
+	i := sort.SearchStrings(s.tokenList, first)
+	for ; i < len(s.tokenList) && strings.HasPrefix(s.tokenList[i], first); i++ {
+		for _, pos := range s.tokens[s.tokenList[i]] {
+			if seen[pos] || (len(words) > 1 && !validators.HasWordPrefix(s.byStart[pos].Title, prefix)) {
+				continue
+			}
+			seen[pos] = true
+			positions = append(positions, pos)
+		}
+	}
+	return s.readPositions(positions)
+}
+
+func (s *IndexStorage) IDPrefix(prefix string) ([]json.RawMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.ensureLoaded()
+	if err != nil {
+		return nil, err
+	}
+	var positions []int
+	for pos, r := range s.byStart {
+		if strings.HasPrefix(r.ID, prefix) {
+			positions = append(positions, pos)
+		}
+	}
+	return s.readPositions(positions)
+}
+
+func (s *IndexStorage) ByPriority(priority string) ([]json.RawMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.ensureLoaded()
+	if err != nil {
+		return nil, err
+	}
+	s.buildPriorities()
+	return s.readPositions(s.byPriority[priority])
+}
+
+func (s *IndexStorage) OpenTasks() ([]json.RawMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.ensureLoaded()
+	if err != nil {
+		return nil, err
+	}
+	var positions []int
+	for pos, r := range s.byStart {
+		if r.Flags&recordTask != 0 && r.Flags&recordDone == 0 {
+			positions = append(positions, pos)
+		}
+	}
+	return s.readPositions(positions)
+}
+
+func (s *IndexStorage) apply(meta []byte, upserts map[string]json.RawMessage, deletes []string) error {
+	s.buildIDs()
+	next := s.file
+	removed := make(map[string]bool)
+	for _, id := range deletes {
+		if pos, ok := s.byID[id]; ok && !removed[id] {
+			removed[id] = true
+			next.Garbage += s.byStart[pos].Length
+		}
+	}
+
+	ids := make([]string, 0, len(upserts))
+	for id := range upserts {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	var changed []indexRecord
+	var bodies [][]byte
+	for _, id := range ids {
+		raw := upserts[id]
+		pos, ok := s.byID[id]
+		if ok && !removed[id] && s.byStart[pos].Hash == hashRecord(raw) {
+			continue
+		}
+		if ok && !removed[id] {
+			removed[id] = true
+			next.Garbage += s.byStart[pos].Length
+		}
+		rec, err := newRecord(id, raw)
+		if err != nil {
+			return err
+		}
+		changed = append(changed, rec)
+		bodies = append(bodies, raw)
+	}
+	if len(removed) == 0 && len(changed) == 0 && bytes.Equal(meta, s.file.Meta) && s.file.Format == indexFormat {
+		return nil
+	}
+
+	next.Format = indexFormat
+	next.Generation++
+	next.Meta = meta
+	next.RecordsFile = fmt.Sprintf(recordFilePattern, next.Generation)
+	if next.DataFile == "" {
+		next.DataFile = fmt.Sprintf(dataFilePattern, next.Generation)
+	}
+	err := os.MkdirAll(s.GetFilename(), 0755)
+	if err != nil {
+		return err
+	}
+	offsets, err := s.appendData(next.DataFile, bodies)
+	if err != nil {
+		return err
+	}
+	records := slices.DeleteFunc(slices.Clone(s.byStart), func(r indexRecord) bool {
+		return removed[r.ID]
+	})
+	for i := range changed {
+		changed[i].Offset = offsets[i]
+		records = append(records, changed[i])
+	}
+	sortRecords(records)
+
+	oldData := ""
+	if next.Garbage > compactMinGarbage && next.Garbage > liveSize(records) {
+		oldData = next.DataFile
+		err = s.compact(&next, records)
+		if err != nil {
+			return err
+		}
+	}
+	err = writeFileAtomic(filepath.Join(s.GetFilename(), next.RecordsFile), func(w io.Writer) error {
+		return encodeRecords(w, records)
+	})
+	if err != nil {
+		return err
+	}
+	index, err := json.Marshal(next)
+	if err != nil {
+		return err
+	}
+	err = writeFileAtomic(filepath.Join(s.GetFilename(), indexFile), func(w io.Writer) error {
+		_, err := w.Write(index)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if s.file.RecordsFile != "" {
+		os.Remove(filepath.Join(s.GetFilename(), s.file.RecordsFile))
+	}
+	if oldData != "" {
+		os.Remove(filepath.Join(s.GetFilename(), oldData))
+	}
+	s.build(next, records)
+	return nil
+}
+
+func (s *IndexStorage) appendData(name string, bodies [][]byte) ([]int64, error) {
+	if len(bodies) == 0 {
+		return nil, nil
+	}
+	f, err := os.OpenFile(filepath.Join(s.GetFilename(), name), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	offset := info.Size()
+	offsets := make([]int64, 0, len(bodies))
+	for _, body := range bodies {
+		n, err := f.Write(body)
+		if err != nil {
+			return nil, err
+		}
+		offsets = append(offsets, offset)
+		offset += int64(n)
+	}
+	return offsets, f.Sync()
+}
+
+// compact copies the live records into a new data file of the next
+// generation and updates their offsets.
+func (s *IndexStorage) compact(next *indexFileData, records []indexRecord) error {
+	raw, err := s.readData(next.DataFile, records)
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf(dataFilePattern, next.Generation)
+	err = writeFileAtomic(filepath.Join(s.GetFilename(), name), func(w io.Writer) error {
+		var offset int64
+		for i := range records {
+			n, err := w.Write(raw[i])
+			if err != nil {
+				return err
+			}
+			records[i].Offset = offset
+			offset += int64(n)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	next.DataFile = name
+	next.Garbage = 0
+	return nil
+}
+
+func (s *IndexStorage) ensureLoaded() error {
+	if s.loaded {
+		return nil
+	}
+	data, err := os.ReadFile(filepath.Join(s.GetFilename(), indexFile))
+	if errors.Is(err, os.ErrNotExist) {
+		s.build(indexFileData{Format: indexFormat}, nil)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var file indexFileData
+	err = json.Unmarshal(data, &file)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(filepath.Join(s.GetFilename(), file.RecordsFile))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	records, err := decodeRecords(bufio.NewReader(f), file.Format)
+	if err != nil {
+		return fmt.Errorf(errRecordsFile, err)
+	}
+	if file.Format < indexFormat {
+		err = s.upgrade(file, records)
+		if err != nil {
+			return err
+		}
+	}
+	s.build(file, records)
+	return nil
+}
+
+// upgrade fills the fields older formats did not keep from the record
+// bodies; the next save writes the records in the current format.
+func (s *IndexStorage) upgrade(file indexFileData, records []indexRecord) error {
+	raw, err := s.readData(file.DataFile, records)
+	if err != nil {
+		return err
+	}
+	for i := range records {
+		rec, err := newRecord(records[i].ID, raw[i])
+		if err != nil {
+			return err
+		}
+		rec.Offset = records[i].Offset
+		records[i] = rec
+	}
+	return nil
+}
+
+func (s *IndexStorage) build(file indexFileData, records []indexRecord) {
+	s.file = file
+	s.byStart = records
+	s.byID = nil
+	s.tokens = nil
+	s.tokenList = nil
+	s.byPriority = nil
+	s.loaded = true
+}
+
+func (s *IndexStorage) meta() []byte {
+	if s.file.Generation == 0 || string(s.file.Meta) == "null" {
+		return []byte{}
+	}
+	return s.file.Meta
+}
+
+func (s *IndexStorage) buildIDs() {
+	if s.byID != nil {
+		return
+	}
+	s.byID = make(map[string]int, len(s.byStart))
+	for pos, r := range s.byStart {
+		s.byID[r.ID] = pos
+	}
+}
+
+func (s *IndexStorage) buildTokens() {
+	if s.tokens != nil {
+		return
+	}
+	s.tokens = make(map[string][]int)
+	for pos, r := range s.byStart {
+		for _, token := range validators.Tokenize(r.Title) {
+			s.tokens[token] = append(s.tokens[token], pos)
+		}
+	}
+	s.tokenList = make([]string, 0, len(s.tokens))
+	for token := range s.tokens {
+		s.tokenList = append(s.tokenList, token)
+	}
+	sort.Strings(s.tokenList)
+}
+
+func (s *IndexStorage) buildPriorities() {
+	if s.byPriority != nil {
+		return
+	}
+	s.byPriority = make(map[string][]int)
+	for pos, r := range s.byStart {
+		s.byPriority[r.Priority] = append(s.byPriority[r.Priority], pos)
+	}
+}
+
+func (s *IndexStorage) readPositions(positions []int) ([]json.RawMessage, error) {
+	sort.Ints(positions)
+	records := make([]indexRecord, 0, len(positions))
+	for _, pos := range positions {
+		records = append(records, s.byStart[pos])
+	}
+	return s.readRecords(records)
+}
+
+func (s *IndexStorage) readRecords(records []indexRecord) ([]json.RawMessage, error) {
+	return s.readData(s.file.DataFile, records)
+}
+
+func (s *IndexStorage) readData(name string, records []indexRecord) ([]json.RawMessage, error) {
+	if len(records) == 0 {
+		return nil, nil
+	}
+	f, err := os.Open(filepath.Join(s.GetFilename(), name))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	result := make([]json.RawMessage, 0, len(records))
+	for _, r := range records {
+		buf := make([]byte, r.Length)
+		_, err := f.ReadAt(buf, r.Offset)
+		if err != nil {
+			return nil, fmt.Errorf(errIndexCorrupted, r.ID)
+		}
+		result = append(result, buf)
+	}
+	return result, nil
+}
+
+func splitDocument(data []byte) ([]byte, map[string]json.RawMessage, error) {
+	var doc map[string]json.RawMessage
+	err := json.Unmarshal(data, &doc)
+	if err != nil {
+		return nil, nil, err
+	}
+	var evs map[string]json.RawMessage
+	if raw, ok := doc[eventsField]; ok && string(raw) != "null" {
+		err = json.Unmarshal(raw, &evs)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	delete(doc, eventsField)
+	meta, err := json.Marshal(doc)
+	if err != nil {
+		return nil, nil, err
+	}
+	return meta, evs, nil
+}
+
+func newRecord(id string, raw []byte) (indexRecord, error) {
+	var fields indexedFields
+	err := json.Unmarshal(raw, &fields)
+	if err != nil {
+		return indexRecord{}, err
+	}
+	rec := indexRecord{
+		ID:       id,
+		Length:   int64(len(raw)),
+		Hash:     hashRecord(raw),
+		Title:    fields.Title,
+		StartAt:  fields.StartAt,
+		Priority: fields.Priority,
+	}
+	if fields.EndAt != nil {
+		rec.EndAt = *fields.EndAt
+	}
+	if fields.Task != nil {
+		rec.Flags |= recordTask
+		if fields.Task.Done {
+			rec.Flags |= recordDone
+		}
+	}
+	return rec, nil
+}
+
+func hashRecord(raw []byte) uint64 {
+	h := fnv.New64a()
+	h.Write(raw)
+	return h.Sum64()
+}
+
+func liveSize(records []indexRecord) int64 {
+	var size int64
+	for _, r := range records {
+		size += r.Length
+	}
+	return size
+}
+
+func encodeRecords(w io.Writer, records []indexRecord) error {
+	bw := bufio.NewWriter(w)
+	buf := make([]byte, 0, 256)
+	buf = binary.AppendUvarint(buf, uint64(len(records)))
+	for _, r := range records {
+		buf = appendString(buf, r.ID)
+		buf = binary.AppendVarint(buf, r.Offset)
+		buf = binary.AppendVarint(buf, r.Length)
+		buf = binary.AppendVarint(buf, r.StartAt.UnixNano())
+		buf = appendString(buf, r.Priority)
+		buf = appendString(buf, r.Title)
+		buf = binary.AppendUvarint(buf, r.Hash)
+		buf = binary.AppendVarint(buf, unixNano(r.EndAt))
+		buf = append(buf, r.Flags)
+		_, err := bw.Write(buf)
+		if err != nil {
+			return err
+		}
+		buf = buf[:0]
+	}
+	_, err := bw.Write(buf)
+	if err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+func decodeRecords(r *bufio.Reader, format int) ([]indexRecord, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]indexRecord, n)
+	for i := range records {
+		rec := &records[i]
+		if rec.ID, err = readString(r); err != nil {
+			return nil, err
+		}
+		if rec.Offset, err = binary.ReadVarint(r); err != nil {
+			return nil, err
+		}
+		if rec.Length, err = binary.ReadVarint(r); err != nil {
+			return nil, err
+		}
+		nanos, err := binary.ReadVarint(r)
+		if err != nil {
+			return nil, err
+		}
+		rec.StartAt = time.Unix(0, nanos)
+		if rec.Priority, err = readString(r); err != nil {
+			return nil, err
+		}
+		if rec.Title, err = readString(r); err != nil {
+			return nil, err
+		}
+		if format < 1 {
+			continue
+		}
+		if rec.Hash, err = binary.ReadUvarint(r); err != nil {
+			return nil, err
+		}
+		if nanos, err = binary.ReadVarint(r); err != nil {
+			return nil, err
+		}
+		if nanos != 0 {
+			rec.EndAt = time.Unix(0, nanos)
+		}
+		if rec.Flags, err = r.ReadByte(); err != nil {
+			return nil, err
+		}
+	}
+	return records, nil
+}
+
+func unixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+func appendString(buf []byte, s string) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+func readString(r *bufio.Reader) (string, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return "", err
+	}
+	buf := make([]byte, n)
+	_, err = io.ReadFull(r, buf)
+	return string(buf), err
+}
+
+func sortRecords(records []indexRecord) {
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].StartAt.Equal(records[j].StartAt) {
+			return records[i].ID < records[j].ID
+		}
+		return records[i].StartAt.Before(records[j].StartAt)
+	})
+}
@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const DefaultMaxSize = 64 << 20
+
+var errSizeLimit = "размер данных %d байт превышает лимит %d байт"
+
+type LimitStorage struct {
+	Store
+	maxSize int
+}
+
+func NewLimitStorage(s Store, maxSize int) *LimitStorage {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+	return &LimitStorage{Store: s, maxSize: maxSize}
+}
+
+func (l *LimitStorage) Unwrap() Store {
+	return l.Store
+}
+
+func (l *LimitStorage) Save(data []byte) error {
+	if len(data) > l.maxSize {
+		return fmt.Errorf(errSizeLimit, len(data), l.maxSize)
+	}
+	return l.Store.Save(data)
+}
+
+func (l *LimitStorage) SaveRecords(meta []byte, upserts map[string]json.RawMessage, deletes []string) error {
+	size := len(meta)
+	for _, raw := range upserts {
+		size += len(raw)
+	}
+	if size > l.maxSize {
+		return fmt.Errorf(errSizeLimit, size, l.maxSize)
+	}
+	return saveRecords(l.Store, meta, upserts, deletes)
+}
+
+func (l *LimitStorage) Load() ([]byte, error) {
+	data, err := l.Store.Load()
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > l.maxSize {
+		return nil, fmt.Errorf(errSizeLimit, len(data), l.maxSize)
+	}
+	return data, nil
+}
@@ -1,11 +1,28 @@
 package storage
 
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
 type Store interface {
 	Save(data []byte) error
 	Load() ([]byte, error)
 	GetFilename() string
 }
 
+// RecordStore saves changed events one by one instead of the whole
+// document; meta is the document without its events.
+type RecordStore interface {
+	Store
+	SaveRecords(meta []byte, upserts map[string]json.RawMessage, deletes []string) error
+}
+
+var errRecordStore = "хранилище %s не поддерживает запись отдельных событий"
+
 type Storage struct {
 	filename string
 }
@@ -13,3 +30,64 @@ type Storage struct {
 func (s *Storage) GetFilename() string {
 	return s.filename
 }
+
+type wrapped interface {
+	Unwrap() Store
+}
+
+func As[T Store](s Store) (T, bool) {
+	for s != nil {
+		if t, ok := s.(T); ok {
+			return t, true
+		}
+		w, ok := s.(wrapped)
+		if !ok {
+			break
+		}
+		s = w.Unwrap()
+	}
+	var zero T
+	return zero, false
+}
+
+func saveRecords(s Store, meta []byte, upserts map[string]json.RawMessage, deletes []string) error {
+	r, ok := s.(RecordStore)
+	if !ok {
+		return fmt.Errorf(errRecordStore, s.GetFilename())
+	}
+	return r.SaveRecords(meta, upserts, deletes)
+}
+
+func writeFileAtomic(filename string, write func(w io.Writer) error) error {
+	err := os.MkdirAll(filepath.Dir(filename), 0755)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName)
+
+	err = tmp.Chmod(0644)
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+	err = write(tmp)
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+	err = tmp.Sync()
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+	err = tmp.Close()
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmpName, filename)
+}
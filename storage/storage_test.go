@@ -0,0 +1,175 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestOpenWrappersRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "calendar.json.gz")
+	s, err := Open("limit+gzip+checksum+file://" + path + "?max_size=1024")
+	if err != nil {
+		t.Fatalf("Ошибка открытия: %v", err)
+	}
+	data := []byte(`{"events":{}}`)
+	if err := s.Save(data); err != nil {
+		t.Fatalf("Ошибка сохранения: %v", err)
+	}
+	got, err := s.Load()
+	if err != nil {
+		t.Fatalf("Ошибка загрузки: %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("Ожидалось %s, получено %s", data, got)
+	}
+	if s.GetFilename() != path {
+		t.Errorf("Неверное имя файла: %s", s.GetFilename())
+	}
+}
+
+func TestChecksumDetectsCorruption(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "calendar.json")
+	s := NewChecksumStorage(NewJsonStorage(path))
+	if err := s.Save([]byte(`{"events":{}}`)); err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := os.ReadFile(path)
+	raw[0] = '['
+	os.WriteFile(path, raw, 0644)
+	if _, err := s.Load(); err == nil {
+		t.Error("Ожидалась ошибка контрольной суммы")
+	}
+}
+
+func TestLimitRejectsLargeData(t *testing.T) {
+	s := NewLimitStorage(NewJsonStorage(filepath.Join(t.TempDir(), "c.json")), 4)
+	if err := s.Save([]byte("12345")); err == nil {
+		t.Error("Ожидалась ошибка превышения лимита")
+	}
+}
+
+func TestOpenUnknownScheme(t *testing.T) {
+	if _, err := Open("brotli+file:///tmp/c.json"); err == nil {
+		t.Error("Ожидалась ошибка неизвестной обёртки")
+	}
+	if _, err := Open("s3:///bucket/c.json"); err == nil {
+		t.Error("Ожидалась ошибка неизвестной схемы")
+	}
+}
+
+func TestZipEntriesAndPackUnpack(t *testing.T) {
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "calendar.zip")
+	z := NewZipStorage(archive)
+	if err := z.Save([]byte(`{"events":{}}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := z.Entry(HistoryEntry).Save([]byte(`{"Logs":[]}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := z.AddAttachment("id-1", "agenda.txt", []byte("agenda")); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := z.Load()
+	if err != nil || string(got) != `{"events":{}}` {
+		t.Errorf("Календарь потерян после записи истории: %s, %v", got, err)
+	}
+	m, err := z.Manifest()
+	if err != nil || m.SchemaVersion != ZipSchemaVersion || len(m.Entries) != 3 {
+		t.Errorf("Неверный манифест: %+v, %v", m, err)
+	}
+
+	out := filepath.Join(dir, "out")
+	if err := Unpack(archive, out); err != nil {
+		t.Fatal(err)
+	}
+	repacked := filepath.Join(dir, "repacked.zip")
+	if err := Pack(out, repacked); err != nil {
+		t.Fatal(err)
+	}
+	names, err := NewZipStorage(repacked).Attachments("id-1")
+	if err != nil || len(names) != 1 || names[0] != "agenda.txt" {
+		t.Errorf("Вложения не сохранились: %v, %v", names, err)
+	}
+
+	for _, id := range []string{"", "..", "../x", `a\b`} {
+		if err := z.AddAttachment(id, "agenda.txt", []byte("x")); err == nil {
+			t.Errorf("Ожидалась ошибка для ID %q", id)
+		}
+	}
+	if err := z.RemoveAttachments("id-1"); err != nil {
+		t.Fatal(err)
+	}
+	if ids, err := z.AttachedEvents(); err != nil || len(ids) != 0 {
+		t.Errorf("Вложения должны быть удалены: %v, %v", ids, err)
+	}
+}
+
+func TestBackupSnapshotsAndRetention(t *testing.T) {
+	dir := t.TempDir()
+	b := NewBackupStorage(NewJsonStorage(filepath.Join(dir, "calendar.json")), filepath.Join(dir, "backups"), 2, 1)
+	at := time.Date(2026, 10, 19, 9, 0, 0, 0, time.Local)
+	b.now = func() time.Time { return at }
+
+	for i := 0; i < 4; i++ {
+		if err := b.Save([]byte(fmt.Sprintf(`{"n":%d}`, i))); err != nil {
+			t.Fatal(err)
+		}
+		at = at.Add(time.Hour)
+	}
+
+	snapshots, err := b.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshots) != 3 {
+		t.Fatalf("Ожидалось 3 снимка, получено %d: %+v", len(snapshots), snapshots)
+	}
+	data, err := b.LoadSnapshot(snapshots[0].ID)
+	if err != nil || string(data) != `{"n":2}` {
+		t.Errorf("Последний снимок должен хранить состояние до записи: %s, %v", data, err)
+	}
+	if _, err := b.LoadSnapshot("../calendar"); err == nil {
+		t.Error("Ожидалась ошибка неверного идентификатора")
+	}
+}
+
+func TestIndexCompactsRewrittenRecords(t *testing.T) {
+	s := NewIndexStorage(filepath.Join(t.TempDir(), "calendar"))
+	if err := s.Save([]byte(`{"version":1,"events":{"a":{"title":"a"},"b":{"title":"b"}}}`)); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		body := fmt.Sprintf(`{"title":"a","description":"%d%s"}`, i, bytes.Repeat([]byte("x"), 10<<10))
+		if err := s.SaveRecords([]byte(`{"version":1}`), map[string]json.RawMessage{"a": json.RawMessage(body)}, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if s.file.DataFile == "events-1.dat" || s.file.Garbage > compactMinGarbage {
+		t.Errorf("Старые версии записей должны вычищаться: %s, мусор %d байт", s.file.DataFile, s.file.Garbage)
+	}
+	if _, err := os.Stat(filepath.Join(s.GetFilename(), "events-1.dat")); !os.IsNotExist(err) {
+		t.Errorf("Прежний файл данных должен удаляться: %v", err)
+	}
+	if err := s.SaveRecords([]byte(`{"version":1}`), nil, []string{"b"}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := NewIndexStorage(s.GetFilename()).Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Events map[string]struct {
+			Description string `json:"description"`
+		} `json:"events"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil || len(doc.Events) != 1 || doc.Events["a"].Description[0] != '9' {
+		t.Errorf("После сжатия загружены неверные данные: %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const schemeSeparator = "://"
+
+var (
+	errUnknownScheme  = "неизвестная схема хранилища: %s"
+	errUnknownWrapper = "неизвестная обёртка хранилища: %s"
+	errEmptyPath      = "не указан путь хранилища: %s"
+	errMaxSize        = "неверный параметр max_size: %s"
+	errIndexWrapper   = "хранилище index не поддерживает обёртку %s"
+)
+
+type opener func(path string, entry string) Store
+
+type wrapper func(s Store, query url.Values) (Store, error)
+
+var openers = map[string]opener{
+	"file": func(path string, _ string) Store { return NewJsonStorage(path) },
+	"zip": func(path string, entry string) Store {
+		if entry != "" {
+			return NewZipStorage(path).Entry(entry)
+		}
+		return NewZipStorage(path)
+	},
+	"index": func(path string, _ string) Store { return NewIndexStorage(path) },
+}
+
+var indexWrappers = map[string]bool{
+	"limit": true,
+}
+
+var wrappers = map[string]wrapper{
+	"gzip": func(s Store, _ url.Values) (Store, error) {
+		return NewGzipStorage(s), nil
+	},
+	"checksum": func(s Store, _ url.Values) (Store, error) {
+		return NewChecksumStorage(s), nil
+	},
+	"limit": func(s Store, query url.Values) (Store, error) {
+		maxSize := 0
+		if v := query.Get("max_size"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf(errMaxSize, v)
+			}
+			maxSize = n
+		}
+		return NewLimitStorage(s, maxSize), nil
+	},
+}
+
+// Open builds a Store from a URI such as "gzip+checksum+file:///path/calendar.json.gz".
+// The last scheme is the backend, the preceding ones are wrappers applied
+// left to right from the outside in. A plain path is opened as a file.
+// For zip archives the fragment selects the entry, e.g. "zip://cal.zip#history.json".
+func Open(uri string) (Store, error) {
+	if !strings.Contains(uri, schemeSeparator) {
+		return NewJsonStorage(uri), nil
+	}
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+	path := u.Host + u.Path
+	if path == "" {
+		return nil, fmt.Errorf(errEmptyPath, uri)
+	}
+
+	schemes := strings.Split(u.Scheme, "+")
+	backend := schemes[len(schemes)-1]
+	open, ok := openers[backend]
+	if !ok {
+		return nil, fmt.Errorf(errUnknownScheme, backend)
+	}
+	s := open(path, u.Fragment)
+
+	for i := len(schemes) - 2; i >= 0; i-- {
+		wrap, ok := wrappers[schemes[i]]
+		if !ok {
+			return nil, fmt.Errorf(errUnknownWrapper, schemes[i])
+		}
+		if backend == "index" && !indexWrappers[schemes[i]] {
+			return nil, fmt.Errorf(errIndexWrapper, schemes[i])
+		}
+		s, err = wrap(s, u.Query())
+		if err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
@@ -2,68 +2,401 @@ package storage
 
 import (
 	"archive/zip"
+	"encoding/json"
 	"errors"
-
+	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	ZipSchemaVersion = 1
+
+	ManifestEntry  = "manifest.json"
+	CalendarEntry  = "calendar.json"
+	HistoryEntry   = "history.json"
+	AttachmentsDir = "attachments/"
+
+	legacyEntry = "data"
+)
+
+var (
+	errEmptyArchive       = errors.New("архив пуст")
+	errNewerArchive       = "архив создан более новой версией (схема %d, поддерживается %d)"
+	errAttachmentNotFound = "вложение %s не найдено"
+	errUnsafeEntry        = "недопустимый путь в архиве: %s"
+	errAttachmentID       = "недопустимый ID события для вложения: %q"
+	errAttachmentName     = "недопустимое имя вложения: %q"
 )
 
+var zipMu sync.Mutex
+
+type Manifest struct {
+	SchemaVersion int       `json:"schema_version"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	Entries       []string  `json:"entries"`
+}
+
 type ZipStorage struct {
 	*Storage
+	entry string
 }
 
 func NewZipStorage(filename string) *ZipStorage {
 	return &ZipStorage{
-		&Storage{filename: filename},
+		Storage: &Storage{filename: filename},
+		entry:   CalendarEntry,
+	}
+}
+
+func (z *ZipStorage) Entry(name string) *ZipStorage {
+	return &ZipStorage{
+		Storage: z.Storage,
+		entry:   name,
 	}
 }
 
 func (z *ZipStorage) Save(data []byte) error {
-	f, err := os.Create(z.GetFilename())
-	if err != nil {
+	zipMu.Lock()
+	defer zipMu.Unlock()
+
+	entries, err := readArchive(z.GetFilename())
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
 		return err
 	}
-	defer f.Close()
+	if entries == nil {
+		entries = make(map[string][]byte)
+	}
+	delete(entries, legacyEntry)
+	entries[z.entry] = data
+	return writeArchive(z.GetFilename(), entries)
+}
 
-	zw := zip.NewWriter(f)
+func (z *ZipStorage) Load() ([]byte, error) {
+	zipMu.Lock()
+	defer zipMu.Unlock()
 
-	w, err := zw.Create("data")
+	entries, err := readArchive(z.GetFilename())
+	if err != nil {
+		return nil, err
+	}
+	if data, ok := entries[z.entry]; ok {
+		return data, nil
+	}
+	if z.entry == CalendarEntry {
+		if data, ok := entries[legacyEntry]; ok {
+			return data, nil
+		}
+		if len(entries) == 0 {
+			return nil, errEmptyArchive
+		}
+	}
+	return []byte{}, nil
+}
+
+func (z *ZipStorage) Manifest() (Manifest, error) {
+	zipMu.Lock()
+	defer zipMu.Unlock()
+
+	entries, err := readArchive(z.GetFilename())
+	if err != nil {
+		return Manifest{}, err
+	}
+	var m Manifest
+	data, ok := entries[ManifestEntry]
+	if !ok {
+		return Manifest{Entries: entryNames(entries)}, nil
+	}
+	err = json.Unmarshal(data, &m)
+	return m, err
+}
+
+func (z *ZipStorage) AddAttachment(eventID string, name string, data []byte) error {
+	entry, err := attachmentPath(eventID, name)
 	if err != nil {
-		zw.Close()
 		return err
 	}
+	return z.Entry(entry).Save(data)
+}
+
+func (z *ZipStorage) Attachment(eventID string, name string) ([]byte, error) {
+	entry, err := attachmentPath(eventID, name)
+	if err != nil {
+		return nil, err
+	}
 
-	_, err = w.Write(data)
+	zipMu.Lock()
+	defer zipMu.Unlock()
+
+	entries, err := readArchive(z.GetFilename())
+	if err != nil {
+		return nil, err
+	}
+	data, ok := entries[entry]
+	if !ok {
+		return nil, fmt.Errorf(errAttachmentNotFound, name)
+	}
+	return data, nil
+}
+
+func (z *ZipStorage) Attachments(eventID string) ([]string, error) {
+	prefix, err := attachmentDir(eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	zipMu.Lock()
+	defer zipMu.Unlock()
+
+	entries, err := readArchive(z.GetFilename())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for name := range entries {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, strings.TrimPrefix(name, prefix))
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// AttachedEvents returns the IDs of events that have at least one attachment.
+func (z *ZipStorage) AttachedEvents() ([]string, error) {
+	zipMu.Lock()
+	defer zipMu.Unlock()
+
+	entries, err := readArchive(z.GetFilename())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for name := range entries {
+		rest, ok := strings.CutPrefix(name, AttachmentsDir)
+		if !ok {
+			continue
+		}
+		id, _, _ := strings.Cut(rest, "/")
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+func (z *ZipStorage) RemoveAttachments(eventIDs ...string) error {
+	prefixes := make([]string, 0, len(eventIDs))
+	for _, id := range eventIDs {
+		prefix, err := attachmentDir(id)
+		if err != nil {
+			return err
+		}
+		prefixes = append(prefixes, prefix)
+	}
+
+	zipMu.Lock()
+	defer zipMu.Unlock()
+
+	entries, err := readArchive(z.GetFilename())
 	if err != nil {
-		zw.Close()
 		return err
 	}
+	removed := false
+	for name := range entries {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(name, prefix) {
+				delete(entries, name)
+				removed = true
+			}
+		}
+	}
+	if !removed {
+		return nil
+	}
+	return writeArchive(z.GetFilename(), entries)
+}
 
-	err = zw.Close()
+func Pack(dir string, archive string) error {
+	entries := make(map[string][]byte)
+	err := filepath.WalkDir(dir, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		if name != CalendarEntry && name != HistoryEntry && !strings.HasPrefix(name, AttachmentsDir) {
+			return nil
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		entries[name] = data
+		return nil
+	})
 	if err != nil {
 		return err
 	}
 
+	zipMu.Lock()
+	defer zipMu.Unlock()
+	return writeArchive(archive, entries)
+}
+
+func Unpack(archive string, dir string) error {
+	zipMu.Lock()
+	entries, err := readArchive(archive)
+	zipMu.Unlock()
+	if err != nil {
+		return err
+	}
+	if data, ok := entries[legacyEntry]; ok {
+		delete(entries, legacyEntry)
+		entries[CalendarEntry] = data
+	}
+	for name, data := range entries {
+		target, err := safeJoin(dir, name)
+		if err != nil {
+			return err
+		}
+		err = os.MkdirAll(filepath.Dir(target), 0755)
+		if err != nil {
+			return err
+		}
+		err = os.WriteFile(target, data, 0644)
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-func (z *ZipStorage) Load() ([]byte, error) {
-	r, err := zip.OpenReader(z.GetFilename())
+func readArchive(filename string) (map[string][]byte, error) {
+	r, err := zip.OpenReader(filename)
 	if err != nil {
 		return nil, err
 	}
 	defer r.Close()
 
-	if len(r.File) == 0 {
-		return nil, errors.New("архив пуст")
+	entries := make(map[string][]byte, len(r.File))
+	for _, file := range r.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		entries[file.Name] = data
+	}
+
+	if data, ok := entries[ManifestEntry]; ok {
+		var m Manifest
+		err = json.Unmarshal(data, &m)
+		if err != nil {
+			return nil, err
+		}
+		if m.SchemaVersion > ZipSchemaVersion {
+			return nil, fmt.Errorf(errNewerArchive, m.SchemaVersion, ZipSchemaVersion)
+		}
 	}
+	return entries, nil
+}
 
-	file := r.File[0]
-	rc, err := file.Open()
+func writeArchive(filename string, entries map[string][]byte) error {
+	delete(entries, ManifestEntry)
+	manifest, err := json.MarshalIndent(Manifest{
+		SchemaVersion: ZipSchemaVersion,
+		UpdatedAt:     time.Now(),
+		Entries:       entryNames(entries),
+	}, "", "  ")
 	if err != nil {
-		return nil, err
+		return err
+	}
+
+	return writeFileAtomic(filename, func(w io.Writer) error {
+		zw := zip.NewWriter(w)
+		names := append([]string{ManifestEntry}, entryNames(entries)...)
+		for _, name := range names {
+			data := manifest
+			if name != ManifestEntry {
+				data = entries[name]
+			}
+			fw, err := zw.Create(name)
+			if err != nil {
+				zw.Close()
+				return err
+			}
+			_, err = fw.Write(data)
+			if err != nil {
+				zw.Close()
+				return err
+			}
+		}
+		return zw.Close()
+	})
+}
+
+func entryNames(entries map[string][]byte) []string {
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		if name != ManifestEntry {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func attachmentDir(eventID string) (string, error) {
+	if eventID == "" || eventID == "." || eventID == ".." || strings.ContainsAny(eventID, `/\`) {
+		return "", fmt.Errorf(errAttachmentID, eventID)
+	}
+	return AttachmentsDir + eventID + "/", nil
+}
+
+func attachmentPath(eventID string, name string) (string, error) {
+	dir, err := attachmentDir(eventID)
+	if err != nil {
+		return "", err
 	}
-	defer rc.Close()
+	base := name[strings.LastIndexAny(name, `/\`)+1:]
+	if base == "" || base == "." || base == ".." {
+		return "", fmt.Errorf(errAttachmentName, name)
+	}
+	return dir + base, nil
+}
 
-	return io.ReadAll(rc)
+func safeJoin(dir string, name string) (string, error) {
+	target := filepath.Join(dir, filepath.FromSlash(name))
+	rel, err := filepath.Rel(dir, target)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf(errUnsafeEntry, name)
+	}
+	return target, nil
 }
@@ -0,0 +1,58 @@
+package validators
+
+import (
+	"fmt"
+	"time"
+)
+
+type DatePolicy string
+
+const (
+	DateFutureOnly DatePolicy = "future_only"
+	DateAllowPast  DatePolicy = "allow_past"
+)
+
+const errUnknownPolicy = "неизвестная политика дат: %s"
+
+type DatePolicies struct {
+	Create DatePolicy `json:"create"`
+	Edit   DatePolicy `json:"edit"`
+	Import DatePolicy `json:"import"`
+}
+
+func DefaultDatePolicies() DatePolicies {
+	return DatePolicies{
+		Create: DateFutureOnly,
+		Edit:   DateFutureOnly,
+		Import: DateAllowPast,
+	}
+}
+
+func (p DatePolicy) Validate() error {
+	switch p {
+	case DateFutureOnly, DateAllowPast:
+		return nil
+	default:
+		return fmt.Errorf(errUnknownPolicy, p)
+	}
+}
+
+func (p DatePolicy) Check(t time.Time) error {
+	if p == DateAllowPast {
+		return nil
+	}
+	if !t.After(Now()) {
+		return ErrDateAlreadyPassed
+	}
+	return nil
+}
+
+func (p DatePolicies) Validate() error {
+	for _, policy := range []DatePolicy{p.Create, p.Edit, p.Import} {
+		err := policy.Validate()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
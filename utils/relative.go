@@ -0,0 +1,282 @@
+package validators
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultRelativeHour = 9
+
+var Now = time.Now
+
+var (
+	errRelativeTime   = "не удалось разобрать время: %s"
+	errRelativeAmount = "не удалось разобрать количество: %s"
+	errRelativeUnit   = "неизвестная единица времени: %s"
+	errRelativeExtra  = errors.New("лишние слова в дате")
+)
+
+var dayWords = map[string]int{
+	"today":       0,
+	"сегодня":     0,
+	"tomorrow":    1,
+	"завтра":      1,
+	"послезавтра": 2,
+}
+
+var weekdayWords = map[string]time.Weekday{
+	"monday": time.Monday, "mon": time.Monday, "понедельник": time.Monday, "пн": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday, "вторник": time.Tuesday, "вт": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday, "среда": time.Wednesday, "среду": time.Wednesday, "ср": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday, "четверг": time.Thursday, "чт": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday, "пятница": time.Friday, "пятницу": time.Friday, "пт": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday, "суббота": time.Saturday, "субботу": time.Saturday, "сб": time.Saturday,
+	"sunday": time.Sunday, "sun": time.Sunday, "воскресенье": time.Sunday, "вс": time.Sunday,
+}
+
+var nextWords = map[string]bool{
+	"next": true, "this": true, "следующий": true, "следующую": true, "следующее": true, "ближайший": true, "ближайшую": true,
+}
+
+var fillerWords = map[string]bool{
+	"at": true, "on": true, "в": true, "во": true,
+}
+
+var unitWords = map[string]time.Duration{
+	"minute": time.Minute, "minutes": time.Minute, "min": time.Minute, "mins": time.Minute,
+	"минуту": time.Minute, "минуты": time.Minute, "минут": time.Minute, "мин": time.Minute,
+	"hour": time.Hour, "hours": time.Hour, "h": time.Hour,
+	"час": time.Hour, "часа": time.Hour, "часов": time.Hour, "ч": time.Hour,
+	"day": 24 * time.Hour, "days": 24 * time.Hour,
+	"день": 24 * time.Hour, "дня": 24 * time.Hour, "дней": 24 * time.Hour,
+	"week": 7 * 24 * time.Hour, "weeks": 7 * 24 * time.Hour,
+	"неделю": 7 * 24 * time.Hour, "недели": 7 * 24 * time.Hour, "недель": 7 * 24 * time.Hour,
+}
+
+var amountWords = map[string]int{
+	"a": 1, "an": 1, "one": 1, "одну": 1, "один": 1, "два": 2, "две": 2, "три": 3, "пять": 5, "десять": 10,
+}
+
+var meridiemWords = map[string]string{
+	"am": "am", "pm": "pm", "утра": "am", "ночи": "am", "дня": "pm", "вечера": "pm",
+}
+
+// ParseRelative resolves phrases like "tomorrow 9am", "next friday 15:00",
+// "in 2 hours", "через 30 минут" or "завтра в 10" against now.
+// It reports false when the input does not look like a relative date, so the
+// caller can fall back to absolute formats.
+func ParseRelative(input string, now time.Time) (time.Time, bool, error) {
+	words := strings.Fields(strings.ToLower(strings.TrimSpace(input)))
+	if len(words) == 0 {
+		return time.Time{}, false, nil
+	}
+
+	switch first := words[0]; {
+	case first == "now" || first == "сейчас":
+		if len(words) > 1 {
+			return time.Time{}, true, errRelativeExtra
+		}
+		return now, true, nil
+	case first == "in" || first == "через":
+		return parseOffset(words[1:], now)
+	}
+
+	rest := words
+	day, hasDay := dayFrom(rest, now)
+	if hasDay {
+		rest = rest[dayWordsUsed(rest):]
+	}
+
+	rest = skipFillers(rest)
+	if len(rest) == 0 {
+		if !hasDay {
+			return time.Time{}, false, nil
+		}
+		return atClock(day, defaultRelativeHour, 0), true, nil
+	}
+
+	hour, minute, ok, err := parseClock(rest)
+	if !ok && !hasDay {
+		return time.Time{}, false, nil
+	}
+	if !ok {
+		return time.Time{}, true, fmt.Errorf(errRelativeTime, strings.Join(rest, " "))
+	}
+	if err != nil {
+		return time.Time{}, true, err
+	}
+	if hasDay {
+		return atClock(day, hour, minute), true, nil
+	}
+	t := atClock(now, hour, minute)
+	if !t.After(now) {
+		t = atClock(now.AddDate(0, 0, 1), hour, minute)
+	}
+	return t, true, nil
+}
+
+// ParseClock resolves a bare time of day such as "17:00", "5pm" or "в 17"
+// on the given day. It reports false when the input is not just a time.
+func ParseClock(input string, day time.Time) (time.Time, bool, error) {
+	words := skipFillers(strings.Fields(strings.ToLower(strings.TrimSpace(input))))
+	if len(words) == 0 {
+		return time.Time{}, false, nil
+	}
+	hour, minute, ok, err := parseClock(words)
+	if !ok || err != nil {
+		return time.Time{}, ok, err
+	}
+	return atClock(day, hour, minute), true, nil
+}
+
+func dayFrom(words []string, now time.Time) (time.Time, bool) {
+	words = skipFillers(words)
+	if len(words) == 0 {
+		return time.Time{}, false
+	}
+	if offset, ok := dayWords[words[0]]; ok {
+		return now.AddDate(0, 0, offset), true
+	}
+	if words[0] == "day" && len(words) >= 3 && words[1] == "after" && words[2] == "tomorrow" {
+		return now.AddDate(0, 0, 2), true
+	}
+	if nextWords[words[0]] && len(words) > 1 {
+		words = words[1:]
+	}
+	if wd, ok := weekdayWords[words[0]]; ok {
+		diff := (int(wd) - int(now.Weekday()) + 7) % 7
+		if diff == 0 {
+			diff = 7
+		}
+		return now.AddDate(0, 0, diff), true
+	}
+	return time.Time{}, false
+}
+
+func dayWordsUsed(words []string) int {
+	used := 0
+	for used < len(words) && fillerWords[words[used]] {
+		used++
+	}
+	switch {
+	case words[used] == "day":
+		return used + 3
+	case nextWords[words[used]] && used+1 < len(words):
+		return used + 2
+	default:
+		return used + 1
+	}
+}
+
+func skipFillers(words []string) []string {
+	for len(words) > 0 && fillerWords[words[0]] {
+		words = words[1:]
+	}
+	return words
+}
+
+func parseOffset(words []string, now time.Time) (time.Time, bool, error) {
+	if len(words) == 0 {
+		return time.Time{}, true, fmt.Errorf(errRelativeAmount, "")
+	}
+	amount := 1
+	if n, err := strconv.Atoi(words[0]); err == nil {
+		amount = n
+		words = words[1:]
+	} else if n, ok := amountWords[words[0]]; ok {
+		amount = n
+		words = words[1:]
+	} else if words[0] == "полчаса" {
+		return now.Add(30 * time.Minute), true, checkExtra(words[1:])
+	}
+	if len(words) == 0 {
+		return time.Time{}, true, fmt.Errorf(errRelativeUnit, "")
+	}
+	unit, ok := unitWords[words[0]]
+	if !ok {
+		return time.Time{}, true, fmt.Errorf(errRelativeUnit, words[0])
+	}
+	words = words[1:]
+
+	if unit < 24*time.Hour {
+		return now.Add(time.Duration(amount) * unit), true, checkExtra(words)
+	}
+	day := now.AddDate(0, 0, amount*int(unit/(24*time.Hour)))
+	words = skipFillers(words)
+	if len(words) == 0 {
+		return day, true, nil
+	}
+	hour, minute, ok, err := parseClock(words)
+	if !ok {
+		return time.Time{}, true, fmt.Errorf(errRelativeTime, strings.Join(words, " "))
+	}
+	if err != nil {
+		return time.Time{}, true, err
+	}
+	return atClock(day, hour, minute), true, nil
+}
+
+func checkExtra(words []string) error {
+	if len(words) > 0 {
+		return errRelativeExtra
+	}
+	return nil
+}
+
+func parseClock(words []string) (int, int, bool, error) {
+	clock := words[0]
+	meridiem := ""
+	for _, suffix := range []string{"am", "pm"} {
+		if strings.HasSuffix(clock, suffix) {
+			clock = strings.TrimSuffix(clock, suffix)
+			meridiem = suffix
+		}
+	}
+	words = words[1:]
+	if meridiem == "" && len(words) > 0 {
+		if m, ok := meridiemWords[words[0]]; ok {
+			meridiem = m
+			words = words[1:]
+		}
+	}
+
+	hourStr, minuteStr, hasMinutes := strings.Cut(clock, ":")
+	hour, err := strconv.Atoi(hourStr)
+	if err != nil {
+		return 0, 0, false, nil
+	}
+	if hour > 23 && !hasMinutes && meridiem == "" {
+		return 0, 0, false, nil
+	}
+	minute := 0
+	if hasMinutes {
+		minute, err = strconv.Atoi(minuteStr)
+		if err != nil || minute > 59 || minute < 0 {
+			return 0, 0, true, fmt.Errorf(errRelativeTime, clock)
+		}
+	}
+	switch meridiem {
+	case "am":
+		if hour == 12 {
+			hour = 0
+		}
+	case "pm":
+		if hour < 12 {
+			hour += 12
+		}
+	}
+	if hour < 0 || hour > 23 {
+		return 0, 0, true, fmt.Errorf(errRelativeTime, clock)
+	}
+	if len(words) > 0 {
+		return 0, 0, true, errRelativeExtra
+	}
+	return hour, minute, true, nil
+}
+
+func atClock(day time.Time, hour int, minute int) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, day.Location())
+}
@@ -0,0 +1,75 @@
+package validators
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRelative(t *testing.T) {
+	now := time.Date(2026, 10, 19, 14, 30, 0, 0, time.Local) // понедельник
+	cases := map[string]time.Time{
+		"tomorrow 9am":         time.Date(2026, 10, 20, 9, 0, 0, 0, time.Local),
+		"next friday 15:00":    time.Date(2026, 10, 23, 15, 0, 0, 0, time.Local),
+		"in 2 hours":           now.Add(2 * time.Hour),
+		"через 30 минут":       now.Add(30 * time.Minute),
+		"завтра в 10":          time.Date(2026, 10, 20, 10, 0, 0, 0, time.Local),
+		"послезавтра":          time.Date(2026, 10, 21, 9, 0, 0, 0, time.Local),
+		"в пятницу в 7 вечера": time.Date(2026, 10, 23, 19, 0, 0, 0, time.Local),
+		"через 2 дня в 18:30":  time.Date(2026, 10, 21, 18, 30, 0, 0, time.Local),
+		"10:00":                time.Date(2026, 10, 20, 10, 0, 0, 0, time.Local),
+		"16:00":                time.Date(2026, 10, 19, 16, 0, 0, 0, time.Local),
+	}
+	for input, want := range cases {
+		got, ok, err := ParseRelative(input, now)
+		if err != nil || !ok {
+			t.Errorf("%q: ошибка %v, распознано %v", input, err, ok)
+			continue
+		}
+		if !got.Equal(want) {
+			t.Errorf("%q: ожидалось %v, получено %v", input, want, got)
+		}
+	}
+}
+
+func TestParseRelativeFallsBack(t *testing.T) {
+	now := time.Date(2026, 10, 19, 14, 30, 0, 0, time.Local)
+	for _, input := range []string{"2025-08-25 15:00", "20251212", "12/12/2025"} {
+		if _, ok, err := ParseRelative(input, now); ok || err != nil {
+			t.Errorf("%q должно разбираться как абсолютная дата: %v", input, err)
+		}
+	}
+	if _, _, err := ParseRelative("завтра в полдень", now); err == nil {
+		t.Error("Ожидалась ошибка разбора времени")
+	}
+}
+
+func TestValidateDateZoneSuffixAndDST(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("нет базы часовых поясов")
+	}
+	Now = func() time.Time { return time.Date(2026, 3, 28, 10, 0, 0, 0, berlin) }
+	defer func() { Now = time.Now }()
+
+	got, err := ValidateDate("tomorrow 10:00 Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Location().String() != "Europe/Berlin" || got.Hour() != 10 {
+		t.Errorf("Пояс или время потеряны: %v", got)
+	}
+	if d := got.Sub(Now()); d != 23*time.Hour {
+		t.Errorf("Переход на летнее время не учтён: %v", d)
+	}
+
+	abs, err := ValidateDate("2026-04-01 15:00 Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if abs.UTC().Hour() != 13 {
+		t.Errorf("Ожидалось 13:00 UTC, получено %v", abs.UTC())
+	}
+	if _, err := ValidateDate("2026-04-01 15:00 Mars/Olympus"); err == nil {
+		t.Error("Ожидалась ошибка неизвестного пояса")
+	}
+}
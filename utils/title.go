@@ -0,0 +1,84 @@
+package validators
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/rivo/uniseg"
+	"golang.org/x/text/unicode/norm"
+)
+
+const zeroWidthJoiner = '\u200d'
+
+var (
+	errTitleChar       = "недопустимый символ %q (U+%04X) в позиции %d"
+	errTitleTooShort   = "длина %d символов меньше минимальной %d"
+	errTitleTooLong    = "длина %d символов больше максимальной %d"
+	errTitleLimits     = errors.New("неверные ограничения длины названия")
+	currentTitlePolicy = DefaultTitlePolicy()
+)
+
+type TitlePolicy struct {
+	MinLength int `json:"min_length"`
+	MaxLength int `json:"max_length"`
+}
+
+func DefaultTitlePolicy() TitlePolicy {
+	return TitlePolicy{
+		MinLength: 3,
+		MaxLength: 50,
+	}
+}
+
+func SetTitlePolicy(p TitlePolicy) error {
+	err := p.Validate()
+	if err != nil {
+		return err
+	}
+	currentTitlePolicy = p
+	return nil
+}
+
+func (p TitlePolicy) Validate() error {
+	if p.MinLength < 1 || p.MaxLength < p.MinLength {
+		return errTitleLimits
+	}
+	return nil
+}
+
+func (p TitlePolicy) Normalize(title string) (string, error) {
+	title = norm.NFC.String(strings.TrimSpace(title))
+	if title == "" {
+		return "", ErrEmptyTitle
+	}
+
+	position := 0
+	g := uniseg.NewGraphemes(title)
+	for g.Next() {
+		position++
+		for _, r := range g.Runes() {
+			if !isTitleRune(r) {
+				return "", fmt.Errorf(errTitleChar, r, r, position)
+			}
+		}
+	}
+
+	switch {
+	case position < p.MinLength:
+		return "", fmt.Errorf(errTitleTooShort, position, p.MinLength)
+	case position > p.MaxLength:
+		return "", fmt.Errorf(errTitleTooLong, position, p.MaxLength)
+	}
+	return title, nil
+}
+
+func ValidateTitle(title string) (string, error) {
+	return currentTitlePolicy.Normalize(title)
+}
+
+func isTitleRune(r rune) bool {
+	return unicode.In(r, unicode.L, unicode.M, unicode.N, unicode.P, unicode.S, unicode.Zs) ||
+		r == zeroWidthJoiner
+}
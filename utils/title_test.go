@@ -0,0 +1,49 @@
+package validators
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTitlePolicyAcceptsUnicode(t *testing.T) {
+	for _, title := range []string{
+		"Call w/ ACME: Q3 review",
+		"1:1 Анна",
+		"Обед 🍕",
+		"Встреча — Σωκράτης",
+		"Семья 👨‍👩‍👧",
+	} {
+		if _, err := ValidateTitle(title); err != nil {
+			t.Errorf("%q: %v", title, err)
+		}
+	}
+}
+
+func TestTitlePolicyNormalizes(t *testing.T) {
+	got, err := ValidateTitle("  Café ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "Café" {
+		t.Errorf("Ожидалась NFC-форма без пробелов, получено %q", got)
+	}
+}
+
+func TestTitlePolicyErrors(t *testing.T) {
+	p := TitlePolicy{MinLength: 3, MaxLength: 5}
+	if _, err := p.Normalize("ab\tcd"); err == nil || !strings.Contains(err.Error(), "U+0009") {
+		t.Errorf("Ожидалась ошибка с указанием символа: %v", err)
+	}
+	if _, err := p.Normalize("👨‍👩‍👧abc"); err != nil {
+		t.Errorf("Длина должна считаться в графемах: %v", err)
+	}
+	if _, err := p.Normalize("ab"); err == nil || !strings.Contains(err.Error(), "меньше") {
+		t.Errorf("Ожидалась ошибка минимальной длины: %v", err)
+	}
+	if _, err := p.Normalize("abcdef"); err == nil || !strings.Contains(err.Error(), "больше") {
+		t.Errorf("Ожидалась ошибка максимальной длины: %v", err)
+	}
+	if _, err := p.Normalize("   "); err != ErrEmptyTitle {
+		t.Errorf("Ожидалась ошибка пустой строки: %v", err)
+	}
+}
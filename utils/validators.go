@@ -2,16 +2,24 @@ package validators
 
 import (
 	"errors"
-	"regexp"
+	"fmt"
+	"net/url"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/araddon/dateparse"
 )
 
 const (
-	validPattern = "^[a-zA-Z\\p{Cyrillic}0-9 ]{3,50}$"
-	timePattern  = "Mon 2006/01/02 - 15:04"
+	timePattern = "Mon 2006/01/02 - 15:04"
+	zonePattern = "MST"
+)
+
+const (
+	errUnknownZone = "неизвестный часовой пояс: %s"
+	errInvalidURL  = "неверная ссылка, ожидается адрес вида https://example.com: %s"
 )
 
 var (
@@ -23,12 +31,13 @@ func FormatDateEvent(date time.Time) string {
 	return date.Format(timePattern)
 }
 
+func FormatDateZone(date time.Time) string {
+	return date.Format(timePattern) + " " + date.Format(zonePattern)
+}
+
 func IsValidTitle(title string) bool {
-	matched, err := regexp.MatchString(validPattern, title)
-	if err != nil {
-		return false
-	}
-	return matched
+	_, err := ValidateTitle(title)
+	return err == nil
 }
 func CheckTitleEmpty(title string) error {
 	if strings.TrimSpace(title) == "" {
@@ -38,21 +47,74 @@ func CheckTitleEmpty(title string) error {
 }
 
 func ValidateDate(dateStr string) (time.Time, error) {
-	t, err := dateparse.ParseAny(dateStr)
+	now := Now()
+	text, loc, err := SplitZone(dateStr)
 	if err != nil {
 		return time.Time{}, err
 	}
+	t, ok, err := ParseRelative(text, now.In(loc))
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !ok {
+		t, err = dateparse.ParseIn(text, loc)
+		if err != nil {
+			return time.Time{}, err
+		}
+	}
+	return t.In(loc), nil
+}
+
+func SplitZone(dateStr string) (string, *time.Location, error) {
+	dateStr = strings.TrimSpace(dateStr)
+	i := strings.LastIndexAny(dateStr, " \t")
+	if i < 0 {
+		return dateStr, time.Local, nil
+	}
+	suffix := dateStr[i+1:]
+	if !looksLikeZone(suffix) {
+		return dateStr, time.Local, nil
+	}
+	loc, err := LoadZone(suffix)
+	if err != nil {
+		return "", nil, err
+	}
+	return strings.TrimSpace(dateStr[:i]), loc, nil
+}
+
+func LoadZone(name string) (*time.Location, error) {
+	if name == "" || name == "Local" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf(errUnknownZone, name)
+	}
+	return loc, nil
+}
 
-	localTime := time.Date(
-		t.Year(), t.Month(), t.Day(),
-		t.Hour(), t.Minute(), t.Second(), t.Nanosecond(),
-		time.Local,
-	)
+func ZoneName(loc *time.Location) string {
+	if loc == nil || loc == time.Local {
+		return ""
+	}
+	return loc.String()
+}
 
-	if !localTime.After(time.Now()) {
-		return time.Time{}, ErrDateAlreadyPassed
+func looksLikeZone(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	if !unicode.IsLetter(r) {
+		return false
 	}
+	return strings.Contains(s, "/") || s == "UTC" || s == "GMT"
+}
 
-	return localTime,
-		nil
+func ValidateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf(errInvalidURL, raw)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf(errInvalidURL, raw)
+	}
+	return nil
 }